@@ -0,0 +1,410 @@
+// Package config populates configuration structs from environment variables,
+// command-line flags and configuration files. The effective configuration is
+// provided as key-value pairs, letting services log it without leaking
+// secrets.
+//
+// Struct fields are considered when they have a config tag, which indicates
+// the configuration name followed by optional flags:
+//
+//	type Config struct {
+//		Port     int           `config:"port" default:"8080"`
+//		Timeout  time.Duration `config:"timeout" default:"5s"`
+//		Password string        `config:"db_password,required,secret"`
+//	}
+//
+// The supported flags are:
+//
+//   - required: loading fails when no value is found for the field. Empty
+//     values, like an environment variable that is set but empty, count as
+//     missing.
+//   - secret: the field value is provided as an obfuscated pair.
+//
+// Values are taken from, in increasing order of precedence, the default tag,
+// the configuration file, environment variables and command-line flags.
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/oops"
+)
+
+const (
+	nameTag    = "config"
+	defaultTag = "default"
+
+	requiredFlag = "required"
+	secretFlag   = "secret"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Option allows to tweak where configuration values are found.
+type Option func(*loader)
+
+// WithEnvPrefix indicates the prefix environment variables use. The
+// environment variable for every configuration name is its upper case
+// version, replacing dots and hyphens with underscores.
+func WithEnvPrefix(prefix string) Option {
+	return func(l *loader) {
+		l.envPrefix = prefix
+	}
+}
+
+// WithFlags indicates the command-line arguments to parse, usually
+// os.Args[1:]. Every configuration name is available as a flag.
+func WithFlags(args []string) Option {
+	return func(l *loader) {
+		l.args = args
+		l.hasFlags = true
+	}
+}
+
+// WithFile indicates a json file that holds configuration values, indexed by
+// configuration name. Arrays are given as comma-separated values, the same
+// way environment variables and flags give them.
+func WithFile(path string) Option {
+	return func(l *loader) {
+		l.file = path
+	}
+}
+
+type loader struct {
+	envPrefix string
+	args      []string
+	hasFlags  bool
+	file      string
+}
+
+type field struct {
+	name       string
+	value      reflect.Value
+	defaultVal string
+	isRequired bool
+	isSecret   bool
+}
+
+// Load populates the struct the given pointer points to and provides the
+// effective configuration as pairs, following the struct fields order.
+// Secret values are provided as obfuscated pairs.
+func Load(dst interface{}, opts ...Option) (kv.Pairs, error) {
+	l := &loader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	fields, err := fieldsOf(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	fromFile, err := l.readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	fromFlags, err := l.parseFlags(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	pairs := make(kv.Pairs, 0, len(fields))
+	for _, f := range fields {
+		text, found := l.lookup(f, fromFile, fromFlags)
+		if (!found || text == "") && f.isRequired {
+			errs = append(errs, oops.With(oops.Invalid("config: %s is required", f.name), kv.New("config.name", f.name)))
+			continue
+		}
+
+		if found {
+			if err := set(f.value, text); err != nil {
+				// Parsing errors can hold the value, which can't be revealed
+				// for secret fields.
+				if f.isSecret {
+					err = fmt.Errorf("invalid %s value", f.value.Type())
+				}
+
+				errs = append(errs, oops.With(oops.Decode("config: decoding %s: %w", f.name, err), kv.New("config.name", f.name)))
+				continue
+			}
+		}
+
+		if f.isSecret {
+			pairs = append(pairs, kv.NewObfuscated(f.name, f.value.Interface()))
+			continue
+		}
+		pairs = append(pairs, kv.New(f.name, f.value.Interface()))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return pairs, nil
+}
+
+// lookup finds the textual value for the given field, considering the
+// sources precedence.
+func (l *loader) lookup(f field, fromFile, fromFlags map[string]string) (string, bool) {
+	if val, exists := fromFlags[f.name]; exists {
+		return val, true
+	}
+	if val, exists := os.LookupEnv(l.envName(f.name)); exists {
+		return val, true
+	}
+	if val, exists := fromFile[f.name]; exists {
+		return val, true
+	}
+	if f.defaultVal != "" {
+		return f.defaultVal, true
+	}
+
+	return "", false
+}
+
+func (l *loader) envName(name string) string {
+	name = strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(name))
+	if l.envPrefix == "" {
+		return name
+	}
+
+	return strings.ToUpper(l.envPrefix) + "_" + name
+}
+
+func (l *loader) readFile() (map[string]string, error) {
+	if l.file == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		return nil, oops.With(oops.NonExistent("config: reading %s: %w", l.file, err), kv.New("config.file", l.file))
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, oops.With(oops.Decode("config: decoding %s: %w", l.file, err), kv.New("config.file", l.file))
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, js := range raw {
+		if string(js) == "null" {
+			continue
+		}
+
+		text, err := fileValue(js)
+		if err != nil {
+			return nil, oops.With(oops.Decode("config: decoding %s in %s: %w", name, l.file, err), kv.New("config.name", name), kv.New("config.file", l.file))
+		}
+
+		values[name] = text
+	}
+
+	return values, nil
+}
+
+// fileValue provides the textual value for the given json value. Strings are
+// unquoted and arrays are given as comma-separated values. Numbers, booleans
+// and objects are kept as they are.
+func fileValue(js json.RawMessage) (string, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(js, &list); err != nil {
+		return scalarValue(js), nil
+	}
+
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		if bytes.HasPrefix(bytes.TrimSpace(item), []byte("[")) {
+			return "", errors.New("nested arrays are not supported")
+		}
+
+		items = append(items, scalarValue(item))
+	}
+
+	return strings.Join(items, ","), nil
+}
+
+func scalarValue(js json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(js, &s); err != nil {
+		return string(bytes.TrimSpace(js))
+	}
+
+	return s
+}
+
+// parseFlags provides the values for the flags that were given.
+func (l *loader) parseFlags(fields []field) (map[string]string, error) {
+	if !l.hasFlags {
+		return nil, nil
+	}
+
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	for _, f := range fields {
+		flags.Var(&textFlag{isBool: f.value.Kind() == reflect.Bool}, f.name, "")
+	}
+
+	if err := flags.Parse(l.args); err != nil {
+		return nil, oops.Invalid("config: parsing flags: %w", err)
+	}
+
+	given := make(map[string]string)
+	flags.Visit(func(fl *flag.Flag) {
+		given[fl.Name] = fl.Value.String()
+	})
+
+	return given, nil
+}
+
+// textFlag keeps the flag value as it is, letting boolean flags be given
+// without a value.
+type textFlag struct {
+	val    string
+	isBool bool
+}
+
+func (f *textFlag) String() string {
+	return f.val
+}
+
+func (f *textFlag) Set(val string) error {
+	f.val = val
+	return nil
+}
+
+func (f *textFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+func fieldsOf(dst interface{}) ([]field, error) {
+	ptr := reflect.ValueOf(dst)
+	if ptr.Kind() != reflect.Pointer || ptr.Elem().Kind() != reflect.Struct {
+		return nil, oops.Invalid("config: a struct pointer is expected, got %T", dst)
+	}
+
+	st := ptr.Elem()
+	var fields []field
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Type().Field(i)
+		tag, exists := sf.Tag.Lookup(nameTag)
+		if !exists || !sf.IsExported() {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(tag, ",")
+		f := field{
+			name:       name,
+			value:      st.Field(i),
+			defaultVal: sf.Tag.Get(defaultTag),
+		}
+		for _, fl := range strings.Split(flags, ",") {
+			switch fl {
+			case requiredFlag:
+				f.isRequired = true
+			case secretFlag:
+				f.isSecret = true
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// set parses the given text into the field value.
+func set(v reflect.Value, text string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+
+		var strs []string
+		for _, s := range strings.Split(text, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				strs = append(strs, s)
+			}
+		}
+		if strs == nil {
+			v.Set(reflect.Zero(v.Type()))
+			break
+		}
+
+		// Elements are set one by one, as slices of named string types
+		// can't be converted from a slice of strings.
+		slice := reflect.MakeSlice(v.Type(), len(strs), len(strs))
+		for i, s := range strs {
+			slice.Index(i).SetString(s)
+		}
+		v.Set(slice)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
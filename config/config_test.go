@@ -0,0 +1,249 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/oops"
+)
+
+type testConfig struct {
+	Name     string        `config:"name" default:"service"`
+	Port     int           `config:"port" default:"8080"`
+	Timeout  time.Duration `config:"timeout" default:"5s"`
+	Debug    bool          `config:"debug"`
+	Ratio    float64       `config:"ratio"`
+	Hosts    []string      `config:"hosts"`
+	IP       net.IP        `config:"ip"`
+	Password string        `config:"db.password,required,secret"`
+
+	ignored string
+	Ignored string
+}
+
+func TestLoadingConfiguration(t *testing.T) {
+	t.Run("using defaults", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "secret")
+
+		var cfg testConfig
+		if _, err := Load(&cfg); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if cfg.Name != "service" || cfg.Port != 8080 || cfg.Timeout != 5*time.Second {
+			t.Fatalf("unexpected configuration, got %+v", cfg)
+		}
+	})
+
+	t.Run("considering the sources precedence", func(t *testing.T) {
+		file := writeFile(t, `{"name": "from-file", "port": 9090, "debug": true, "ratio": 0.5}`)
+		t.Setenv("APP_PORT", "7070")
+		t.Setenv("APP_HOSTS", "a, b")
+		t.Setenv("APP_DB_PASSWORD", "secret")
+
+		var cfg testConfig
+		_, err := Load(
+			&cfg,
+			WithFile(file),
+			WithEnvPrefix("app"),
+			WithFlags([]string{"-timeout", "1m", "-debug=false", "-ip", "127.0.0.1"}),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		want := testConfig{
+			Name:     "from-file",
+			Port:     7070,
+			Timeout:  time.Minute,
+			Debug:    false,
+			Ratio:    0.5,
+			Hosts:    []string{"a", "b"},
+			IP:       net.ParseIP("127.0.0.1"),
+			Password: "secret",
+		}
+		if cfg.Name != want.Name || cfg.Port != want.Port || cfg.Timeout != want.Timeout ||
+			cfg.Debug != want.Debug || cfg.Ratio != want.Ratio || strings.Join(cfg.Hosts, ",") != "a,b" ||
+			!cfg.IP.Equal(want.IP) || cfg.Password != want.Password {
+			t.Fatalf("unexpected configuration, got %+v, want %+v", cfg, want)
+		}
+	})
+
+	t.Run("reading lists and numbers from the file", func(t *testing.T) {
+		file := writeFile(t, `{"hosts": ["a", "b,c"], "port": 9090, "ratio": 1e-3, "debug": true, "ip": null}`)
+		t.Setenv("DB_PASSWORD", "secret")
+
+		var cfg testConfig
+		if _, err := Load(&cfg, WithFile(file)); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if got := strings.Join(cfg.Hosts, "|"); got != "a|b|c" {
+			t.Fatalf("unexpected hosts, got %s", got)
+		}
+		if cfg.Port != 9090 || cfg.Ratio != 0.001 || !cfg.Debug || cfg.IP != nil {
+			t.Fatalf("unexpected configuration, got %+v", cfg)
+		}
+	})
+
+	t.Run("using named element types", func(t *testing.T) {
+		type region string
+		t.Setenv("REGIONS", "eu, us")
+
+		var cfg struct {
+			Regions []region `config:"regions"`
+		}
+		if _, err := Load(&cfg); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if len(cfg.Regions) != 2 || cfg.Regions[0] != "eu" || cfg.Regions[1] != "us" {
+			t.Fatalf("unexpected regions, got %v", cfg.Regions)
+		}
+	})
+
+	t.Run("giving boolean flags without value", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "secret")
+
+		var cfg testConfig
+		if _, err := Load(&cfg, WithFlags([]string{"-debug"})); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if !cfg.Debug {
+			t.Fatal("the boolean flag had to be set")
+		}
+	})
+}
+
+func TestEffectiveConfiguration(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+
+	var cfg testConfig
+	pairs, err := Load(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+
+	var names []string
+	for _, pair := range pairs {
+		names = append(names, pair.Name())
+	}
+	if got, want := strings.Join(names, ","), "name,port,timeout,debug,ratio,hosts,ip,db.password"; got != want {
+		t.Fatalf("unexpected pairs, got %s, want %s", got, want)
+	}
+
+	if got, _ := pairs.Get("port"); got.Int() != 8080 {
+		t.Fatalf("unexpected port, got %v", got.Value())
+	}
+
+	password, _ := pairs.Get("db.password")
+	if !password.IsObfuscated() || password.Value() == "secret" {
+		t.Fatalf("secret values had to be obfuscated, got %v", password.Value())
+	}
+	if got := kv.Reveal(password, "testing"); got != "secret" {
+		t.Fatalf("unexpected secret value, got %v", got)
+	}
+}
+
+func TestLoadingErrors(t *testing.T) {
+	tests := map[string]struct {
+		dst  interface{}
+		env  map[string]string
+		opts []Option
+		is   error
+	}{
+		"missing required value": {
+			dst: &testConfig{},
+			is:  oops.ErrInvalid,
+		},
+		"empty required value": {
+			dst: &testConfig{},
+			env: map[string]string{"DB_PASSWORD": ""},
+			is:  oops.ErrInvalid,
+		},
+		"nested file arrays": {
+			dst:  &testConfig{},
+			opts: []Option{WithFile(writeFile(t, `{"hosts": [["a"]]}`))},
+			is:   oops.ErrDecode,
+		},
+		"invalid value": {
+			dst: &testConfig{},
+			env: map[string]string{"DB_PASSWORD": "secret", "PORT": "port"},
+			is:  oops.ErrDecode,
+		},
+		"unknown flag": {
+			dst:  &testConfig{},
+			env:  map[string]string{"DB_PASSWORD": "secret"},
+			opts: []Option{WithFlags([]string{"-unknown"})},
+			is:   oops.ErrInvalid,
+		},
+		"missing file": {
+			dst:  &testConfig{},
+			opts: []Option{WithFile(filepath.Join(t.TempDir(), "missing.json"))},
+			is:   oops.ErrNonExistent,
+		},
+		"not a struct pointer": {
+			dst: testConfig{},
+			is:  oops.ErrInvalid,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, val := range test.env {
+				t.Setenv(key, val)
+			}
+
+			_, err := Load(test.dst, test.opts...)
+			if !errors.Is(err, test.is) {
+				t.Fatalf("unexpected error, got %v, want %s", err, test.is)
+			}
+		})
+	}
+
+	t.Run("keeping secret values out of errors", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "secret")
+		t.Setenv("DB_PORT", "hunter2secret")
+
+		var cfg struct {
+			Port int `config:"db_port,secret"`
+		}
+		_, err := Load(&cfg)
+		if !errors.Is(err, oops.ErrDecode) {
+			t.Fatalf("unexpected error, got %v", err)
+		}
+		if strings.Contains(fmt.Sprintf("%+v", err), "hunter2secret") {
+			t.Fatalf("secret values can't be revealed, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "db_port") {
+			t.Fatalf("the configuration name had to be provided, got %v", err)
+		}
+	})
+
+	t.Run("providing the configuration name", func(t *testing.T) {
+		_, err := Load(&testConfig{})
+
+		if pair, exists := oops.Detail(err, "config.name"); !exists || pair.String() != "db.password" {
+			t.Fatalf("unexpected configuration name, got %s", pair.String())
+		}
+	})
+}
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(name, []byte(contents), 0o600); err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+
+	return name
+}
@@ -1,15 +1,16 @@
 module github.com/thisiserico/golib
 
-go 1.19
+go 1.21
 
 require (
 	github.com/apex/log v1.1.1
-	github.com/google/go-cmp v0.5.7
-	github.com/google/uuid v1.1.1
+	github.com/google/go-cmp v0.5.9
+	github.com/google/uuid v1.3.0
 	github.com/segmentio/redis-go v0.3.0
 	go.opentelemetry.io/otel v1.6.0
 	go.opentelemetry.io/otel/sdk v1.6.0
 	go.opentelemetry.io/otel/trace v1.6.0
+	google.golang.org/grpc v1.58.3
 )
 
 require (
@@ -17,10 +18,12 @@ require (
 	github.com/fatih/color v1.7.0 // indirect
 	github.com/go-logr/logr v1.2.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/mattn/go-colorable v0.1.2 // indirect
 	github.com/mattn/go-isatty v0.0.8 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/segmentio/fasthash v1.0.3 // indirect
 	github.com/segmentio/objconv v1.0.1 // indirect
-	golang.org/x/sys v0.0.0-20210423185535-09eb48e85fd7 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
 )
@@ -3,6 +3,7 @@ package halt
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -20,13 +21,26 @@ type Halter interface {
 var _ Halter = &halter{}
 
 type halter struct {
-	ctx context.Context
-	log logger.Log
+	ctx     context.Context
+	log     logger.Log
+	closers []io.Closer
+}
+
+// Option allows to tweak the halter behavior.
+type Option func(*halter)
+
+// WithClosers indicates elements to close once a shutdown is requested, in
+// the given order. An asynchronous log writer is a good example, so that no
+// log lines are lost.
+func WithClosers(closers ...io.Closer) Option {
+	return func(h *halter) {
+		h.closers = append(h.closers, closers...)
+	}
 }
 
 // New configures and returns the context to use when shutting down.
-func New(ctx context.Context, log logger.Log) (context.Context, Halter) {
-	stop := make(chan os.Signal)
+func New(ctx context.Context, log logger.Log, opts ...Option) (context.Context, Halter) {
+	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -35,13 +49,25 @@ func New(ctx context.Context, log logger.Log) (context.Context, Halter) {
 		cancel()
 	}()
 
-	return ctx, &halter{
+	h := &halter{
 		ctx: ctx,
 		log: log,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return ctx, h
 }
 
 func (s *halter) Wait() {
 	<-s.ctx.Done()
 	s.log(s.ctx, "stopper gracefully shuting down")
+
+	for _, closer := range s.closers {
+		if err := closer.Close(); err != nil {
+			s.log(s.ctx, err)
+		}
+	}
 }
@@ -0,0 +1,153 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// ToBaggageHeader returns the W3C Baggage header value holding the
+// attributes that exist in the context, considering only the allowed keys.
+// Obfuscated attributes are never included.
+func ToBaggageHeader(ctx context.Context, allowed ...string) (string, error) {
+	pairs := allowedAttributes(ctx, allowed)
+
+	members := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		val, err := textValue(pair)
+		if err != nil {
+			return "", err
+		}
+
+		members = append(members, pair.Name()+"="+url.PathEscape(val))
+	}
+
+	return strings.Join(members, ","), nil
+}
+
+// FromBaggageHeader sets into the context the attributes that the given W3C
+// Baggage header value holds, considering only the allowed keys. Members for
+// other keys are ignored. An error is returned for members that can't be
+// decoded, though the rest of them are still set. Invalid correlation IDs are
+// not set either, see ValidateCorrelationID.
+func FromBaggageHeader(ctx context.Context, header string, allowed ...string) (context.Context, error) {
+	var errs []error
+	for _, member := range strings.Split(header, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		name, val, found := strings.Cut(member, "=")
+		if !found {
+			continue
+		}
+
+		val, err := url.PathUnescape(strings.TrimSpace(val))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("kv: decoding baggage member %s: %w", name, err))
+			continue
+		}
+
+		ctx, err = setAllowedAttribute(ctx, strings.TrimSpace(name), val, allowed)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return ctx, errors.Join(errs...)
+}
+
+// ToBaggage returns the opentelemetry baggage holding the attributes that
+// exist in the context, considering only the allowed keys. Obfuscated
+// attributes are never included. Given the baggage package requirements,
+// member values are percent-encoded.
+func ToBaggage(ctx context.Context, allowed ...string) (baggage.Baggage, error) {
+	var members []baggage.Member
+	for _, pair := range allowedAttributes(ctx, allowed) {
+		val, err := textValue(pair)
+		if err != nil {
+			return baggage.Baggage{}, err
+		}
+
+		member, err := baggage.NewMember(pair.Name(), url.PathEscape(val))
+		if err != nil {
+			return baggage.Baggage{}, fmt.Errorf("kv: encoding baggage member %s: %w", pair.Name(), err)
+		}
+
+		members = append(members, member)
+	}
+
+	return baggage.New(members...)
+}
+
+// FromBaggage sets into the context the attributes that the given
+// opentelemetry baggage holds, considering only the allowed keys. Members for
+// other keys are ignored. An error is returned for members that can't be
+// decoded, though the rest of them are still set. Invalid correlation IDs are
+// not set either, see ValidateCorrelationID.
+func FromBaggage(ctx context.Context, b baggage.Baggage, allowed ...string) (context.Context, error) {
+	var errs []error
+	for _, member := range b.Members() {
+		val, err := url.PathUnescape(member.Value())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("kv: decoding baggage member %s: %w", member.Key(), err))
+			continue
+		}
+
+		ctx, err = setAllowedAttribute(ctx, member.Key(), val, allowed)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return ctx, errors.Join(errs...)
+}
+
+func allowedAttributes(ctx context.Context, allowed []string) Pairs {
+	var pairs Pairs
+	for _, pair := range AllAttributes(ctx) {
+		if pair.raw == nil || pair.isObfuscated || !contains(allowed, pair.Name()) {
+			continue
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs
+}
+
+func setAllowedAttribute(ctx context.Context, name, val string, allowed []string) (context.Context, error) {
+	if !contains(allowed, name) {
+		return ctx, nil
+	}
+
+	k, exists := lookupKey(name)
+	if !exists {
+		return ctx, nil
+	}
+
+	if name == correlationIDKey.Name() {
+		if err := ValidateCorrelationID(val); err != nil {
+			return ctx, fmt.Errorf("kv: decoding baggage member %s: %w", name, err)
+		}
+	}
+
+	return k.decodeText(ctx, val)
+}
+
+// textValue encodes the pair value as text: strings are kept as they are,
+// any other value is json encoded.
+func textValue(pair Pair) (string, error) {
+	if s, ok := pair.raw.(string); ok {
+		return s, nil
+	}
+
+	js, err := json.Marshal(pair.raw)
+	if err != nil {
+		return "", fmt.Errorf("kv: encoding %s: %w", pair.Name(), err)
+	}
+
+	return string(js), nil
+}
@@ -0,0 +1,127 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+var allowedNames = []string{"ctx.correlation_id", "ctx.is_dry_run", "test.tenant", "test.retries"}
+
+func TestBaggageHeader(t *testing.T) {
+	t.Run("encoding and decoding attributes", func(t *testing.T) {
+		ctx := SetStaticAttributes(context.Background(), "build_id", "host", "name")
+		ctx = SetDynamicAttributes(ctx, "correlation_id", true)
+		ctx = tenantKey.Set(ctx, "a tenant, with; symbols=")
+		ctx = retriesKey.Set(ctx, 3)
+
+		header, err := ToBaggageHeader(ctx, allowedNames...)
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+		if strings.Contains(header, "svc.") {
+			t.Fatalf("non allowed attributes had to be excluded, got %s", header)
+		}
+
+		ctx, err = FromBaggageHeader(context.Background(), header, allowedNames...)
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+		assertPropagatedAttributes(t, ctx)
+	})
+
+	t.Run("decoding a header from another service", func(t *testing.T) {
+		header := "ctx.correlation_id=correlation_id, ctx.is_dry_run=true;prop, test.retries=3, test.tenant=a%20tenant%2C%20with%3B%20symbols%3D, svc.name=leaked, unknown=value"
+
+		ctx, err := FromBaggageHeader(context.Background(), header, append(allowedNames, "unknown")...)
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+		assertPropagatedAttributes(t, ctx)
+
+		if got := AllAttributes(ctx)[2]; got.Value() != nil {
+			t.Fatalf("non allowed attributes had to be ignored, got %v", got.Value())
+		}
+	})
+
+	t.Run("decoding invalid members", func(t *testing.T) {
+		ctx, err := FromBaggageHeader(context.Background(), "test.retries=three,ctx.correlation_id=correlation_id", allowedNames...)
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+
+		if got := CorrelationID(ctx).String(); got != "correlation_id" {
+			t.Fatalf("valid members had to be decoded, got %s", got)
+		}
+	})
+
+	t.Run("decoding invalid correlation IDs", func(t *testing.T) {
+		for _, id := range []string{"", "with%20spaces", strings.Repeat("a", MaxCorrelationIDLength+1)} {
+			ctx, err := FromBaggageHeader(context.Background(), "ctx.correlation_id="+id+",ctx.is_dry_run=true", allowedNames...)
+			if !errors.Is(err, ErrInvalidCorrelationID) {
+				t.Fatalf("unexpected error for %q, got %v", id, err)
+			}
+
+			if got, _ := correlationIDKey.Get(ctx); got != "" {
+				t.Fatalf("the correlation ID had to be dropped, got %s", got)
+			}
+			if !IsDryRun(ctx).Bool() {
+				t.Fatal("valid members had to be decoded")
+			}
+		}
+	})
+}
+
+func TestOpentelemetryBaggage(t *testing.T) {
+	ctx := SetDynamicAttributes(context.Background(), "correlation_id", true)
+	ctx = tenantKey.Set(ctx, "a tenant, with; symbols=")
+	ctx = retriesKey.Set(ctx, 3)
+	ctx = SetStaticAttributes(ctx, "build_id", "host", "name")
+
+	b, err := ToBaggage(ctx, allowedNames...)
+	if err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+	if got := b.Len(); got != 4 {
+		t.Fatalf("unexpected number of baggage members, want 4, got %d", got)
+	}
+
+	ctx, err = FromBaggage(context.Background(), b, allowedNames...)
+	if err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+	assertPropagatedAttributes(t, ctx)
+
+	t.Run("decoding an invalid correlation ID", func(t *testing.T) {
+		member, _ := baggage.NewMember("ctx.correlation_id", strings.Repeat("a", MaxCorrelationIDLength+1))
+		b, _ := baggage.New(member)
+
+		ctx, err := FromBaggage(context.Background(), b, allowedNames...)
+		if !errors.Is(err, ErrInvalidCorrelationID) {
+			t.Fatalf("unexpected error, got %v", err)
+		}
+		if got, _ := correlationIDKey.Get(ctx); got != "" {
+			t.Fatalf("the correlation ID had to be dropped, got %s", got)
+		}
+	})
+}
+
+func assertPropagatedAttributes(t *testing.T, ctx context.Context) {
+	t.Helper()
+
+	if got := CorrelationID(ctx).String(); got != "correlation_id" {
+		t.Errorf("unexpected correlation ID, got %s", got)
+	}
+	if got := IsDryRun(ctx).Bool(); !got {
+		t.Errorf("unexpected is dry run, got %t", got)
+	}
+	if got, _ := tenantKey.Get(ctx); got != "a tenant, with; symbols=" {
+		t.Errorf("unexpected tenant, got %s", got)
+	}
+	if got, _ := retriesKey.Get(ctx); got != 3 {
+		t.Errorf("unexpected retries, got %d", got)
+	}
+}
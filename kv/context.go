@@ -2,34 +2,59 @@ package kv
 
 import "context"
 
-const (
-	buildIDKey     = key("svc.build_id")
-	serviceHostKey = key("svc.host")
-	serviceNameKey = key("svc.name")
+var (
+	buildIDKey     = newBuiltInKey[string]("svc.build_id")
+	serviceHostKey = newBuiltInKey[string]("svc.host")
+	serviceNameKey = newBuiltInKey[string]("svc.name")
 
-	correlationIDKey = key("ctx.correlation_id")
-	isDryRunKey      = key("ctx.is_dry_run")
+	correlationIDKey = newBuiltInKey[string]("ctx.correlation_id")
+	isDryRunKey      = newBuiltInKey[bool]("ctx.is_dry_run")
 )
 
-type key string
+// attributesKey identifies the attribute set within a context.
+type attributesKey struct{}
 
-func get(ctx context.Context, k key) Pair {
-	val := ctx.Value(k)
-	if val == nil {
-		val = New(string(k), nil)
+// attributes is the set of attributes held in a context, indexed by key
+// name. It's never modified once in a context, a copy is made instead.
+type attributes map[string]interface{}
+
+func attributesFrom(ctx context.Context) attributes {
+	attrs, _ := ctx.Value(attributesKey{}).(attributes)
+	return attrs
+}
+
+// setAttributes returns a copy of the given context holding a copy of its
+// attribute set that includes the given values.
+func setAttributes(ctx context.Context, values map[string]interface{}) context.Context {
+	current := attributesFrom(ctx)
+
+	attrs := make(attributes, len(current)+len(values))
+	for name, val := range current {
+		attrs[name] = val
+	}
+	for name, val := range values {
+		attrs[name] = val
 	}
 
-	return val.(Pair)
+	return context.WithValue(ctx, attributesKey{}, attrs)
 }
 
 // DecorateWithAttributes adds the static attributes as values in the
 // resulting context.
 func DecorateWithAttributes(inUse, background context.Context) context.Context {
-	inUse = context.WithValue(inUse, buildIDKey, get(background, buildIDKey))
-	inUse = context.WithValue(inUse, serviceHostKey, get(background, serviceHostKey))
-	inUse = context.WithValue(inUse, serviceNameKey, get(background, serviceNameKey))
+	static := attributesFrom(background)
 
-	return inUse
+	values := make(map[string]interface{})
+	for _, name := range []string{
+		buildIDKey.name, serviceHostKey.name, serviceNameKey.name,
+		goVersionKey.name, pidKey.name, containerIDKey.name,
+	} {
+		if val, exists := static[name]; exists {
+			values[name] = val
+		}
+	}
+
+	return setAttributes(inUse, values)
 }
 
 // SetStaticAttributes sets program attributes (build ID, service host and service name)
@@ -38,11 +63,11 @@ func SetStaticAttributes(
 	ctx context.Context,
 	buildID, serviceHost, serviceName string,
 ) context.Context {
-	ctx = context.WithValue(ctx, buildIDKey, New(string(buildIDKey), buildID))
-	ctx = context.WithValue(ctx, serviceHostKey, New(string(serviceHostKey), serviceHost))
-	ctx = context.WithValue(ctx, serviceNameKey, New(string(serviceNameKey), serviceName))
-
-	return ctx
+	return setAttributes(ctx, map[string]interface{}{
+		buildIDKey.name:     buildID,
+		serviceHostKey.name: serviceHost,
+		serviceNameKey.name: serviceName,
+	})
 }
 
 // SetDynamicAttributes sets request attributes (correlation ID and the is dry run)
@@ -52,29 +77,33 @@ func SetDynamicAttributes(
 	correlationID string,
 	isDryRun bool,
 ) context.Context {
-	ctx = context.WithValue(ctx, correlationIDKey, New(string(correlationIDKey), correlationID))
-	ctx = context.WithValue(ctx, isDryRunKey, New(string(isDryRunKey), isDryRun))
-
-	return ctx
+	return setAttributes(ctx, map[string]interface{}{
+		correlationIDKey.name: correlationID,
+		isDryRunKey.name:      isDryRun,
+	})
 }
 
-// AllAttributes returns all the known pairs that exist in the context.
-func AllAttributes(ctx context.Context) []Pair {
-	return []Pair{
-		get(ctx, buildIDKey),
-		get(ctx, serviceHostKey),
-		get(ctx, serviceNameKey),
-		get(ctx, correlationIDKey),
-		get(ctx, isDryRunKey),
+// AllAttributes returns all the known pairs that exist in the context,
+// including the ones for keys created by clients. Pairs for attributes
+// that don't exist hold a nil value.
+func AllAttributes(ctx context.Context) Pairs {
+	attrs := attributesFrom(ctx)
+	keys := registeredKeys()
+
+	pairs := make(Pairs, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k.pair(attrs))
 	}
+
+	return pairs
 }
 
 // CorrelationID returns pair holding that information from the given context.
 func CorrelationID(ctx context.Context) Pair {
-	return get(ctx, correlationIDKey)
+	return correlationIDKey.pair(attributesFrom(ctx))
 }
 
 // IsDryRun returns pair holding that information from the given context.
 func IsDryRun(ctx context.Context) Pair {
-	return get(ctx, isDryRunKey)
+	return isDryRunKey.pair(attributesFrom(ctx))
 }
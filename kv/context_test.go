@@ -41,6 +41,10 @@ func TestContextPairs(t *testing.T) {
 			case "ctx.is_dry_run":
 				want = isDryRun
 				got = attr.Bool()
+
+			case goVersionKey.Name(), pidKey.Name(), containerIDKey.Name(),
+				tenantKey.Name(), retriesKey.Name():
+				continue
 			}
 
 			if want == nil || want != got {
@@ -78,7 +82,9 @@ func TestContextPairs(t *testing.T) {
 				want = serviceName
 				got = attr.String()
 
-			case "ctx.correlation_id", "ctx.is_dry_run":
+			case "ctx.correlation_id", "ctx.is_dry_run",
+				goVersionKey.Name(), pidKey.Name(), containerIDKey.Name(),
+				tenantKey.Name(), retriesKey.Name():
 				continue
 			}
 
@@ -104,3 +110,36 @@ func TestContextPairs(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkSettingAttributes(b *testing.B) {
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ctx := SetStaticAttributes(ctx, "build_id", "service_host", "service_name")
+		_ = SetDynamicAttributes(ctx, "correlation_id", true)
+	}
+}
+
+func BenchmarkAllAttributes(b *testing.B) {
+	ctx := context.Background()
+	ctx = SetStaticAttributes(ctx, "build_id", "service_host", "service_name")
+	ctx = SetDynamicAttributes(ctx, "correlation_id", true)
+	ctx = retriesKey.Set(ctx, 3)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = AllAttributes(ctx)
+	}
+}
+
+func BenchmarkGettingAnAttribute(b *testing.B) {
+	ctx := context.Background()
+	ctx = SetStaticAttributes(ctx, "build_id", "service_host", "service_name")
+	ctx = SetDynamicAttributes(ctx, "correlation_id", true)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = CorrelationID(ctx)
+	}
+}
@@ -0,0 +1,127 @@
+package kv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCorrelationIDLength is the maximum length a correlation ID can have.
+const MaxCorrelationIDLength = 128
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ErrInvalidCorrelationID indicates that a correlation ID is empty, too long
+// or contains unexpected characters.
+var ErrInvalidCorrelationID = errors.New("kv: invalid correlation ID")
+
+var (
+	generatorLock sync.RWMutex
+	generator     CorrelationIDGenerator = UUIDv4
+)
+
+// CorrelationIDGenerator produces new correlation IDs.
+type CorrelationIDGenerator func() string
+
+// UUIDv4 generates random UUIDs.
+func UUIDv4() string {
+	return uuid.New().String()
+}
+
+// UUIDv7 generates time ordered UUIDs, as defined in RFC 9562.
+func UUIDv7() string {
+	var id uuid.UUID
+	_, _ = rand.Read(id[6:])
+
+	ms := uint64(time.Now().UnixMilli())
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+
+	id[6] = id[6]&0x0f | 0x70
+	id[8] = id[8]&0x3f | 0x80
+
+	return id.String()
+}
+
+// ULID generates lexicographically sortable identifiers, as defined in
+// https://github.com/ulid/spec.
+func ULID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[6:])
+
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint16(id[0:], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:], uint32(ms))
+
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	// 26 characters of 5 bits each encode the 128 bits, with the first
+	// character only using 3 of them.
+	var text [26]byte
+	for i := len(text) - 1; i >= 0; i-- {
+		text[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(text[:])
+}
+
+// SetCorrelationIDGenerator sets the generator EnsureCorrelationID uses.
+// Defaults to UUIDv4.
+func SetCorrelationIDGenerator(g CorrelationIDGenerator) {
+	generatorLock.Lock()
+	defer generatorLock.Unlock()
+
+	generator = g
+}
+
+// ValidateCorrelationID makes sure that the given correlation ID is not
+// empty, is not longer than MaxCorrelationIDLength and only contains
+// letters, digits, dots, colons, hyphens and underscores. Correlation IDs
+// that come from untrusted sources are expected to be validated.
+func ValidateCorrelationID(id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidCorrelationID)
+	}
+	if len(id) > MaxCorrelationIDLength {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidCorrelationID, MaxCorrelationIDLength)
+	}
+
+	for _, r := range id {
+		isLetter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		isDigit := r >= '0' && r <= '9'
+		isSymbol := r == '.' || r == ':' || r == '-' || r == '_'
+		if !isLetter && !isDigit && !isSymbol {
+			return fmt.Errorf("%w: unexpected character %q", ErrInvalidCorrelationID, r)
+		}
+	}
+
+	return nil
+}
+
+// EnsureCorrelationID returns a context that holds a valid correlation ID.
+// An existing valid correlation ID is kept, otherwise a new one is created
+// using the configured generator.
+func EnsureCorrelationID(ctx context.Context) context.Context {
+	if id, _ := correlationIDKey.Get(ctx); ValidateCorrelationID(id) == nil {
+		return ctx
+	}
+
+	generatorLock.RLock()
+	generate := generator
+	generatorLock.RUnlock()
+
+	return correlationIDKey.Set(ctx, generate())
+}
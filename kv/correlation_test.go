@@ -0,0 +1,116 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCorrelationIDGenerators(t *testing.T) {
+	tests := map[string]struct {
+		generate CorrelationIDGenerator
+		format   *regexp.Regexp
+	}{
+		"uuid v4": {
+			generate: UUIDv4,
+			format:   regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		},
+		"uuid v7": {
+			generate: UUIDv7,
+			format:   regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		},
+		"ulid": {
+			generate: ULID,
+			format:   regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			first, second := test.generate(), test.generate()
+			if first == second {
+				t.Fatalf("generated IDs had to be unique, got %s twice", first)
+			}
+
+			for _, id := range []string{first, second} {
+				if !test.format.MatchString(id) {
+					t.Fatalf("unexpected format, got %s", id)
+				}
+				if err := ValidateCorrelationID(id); err != nil {
+					t.Fatalf("generated IDs had to be valid, got %s", err)
+				}
+			}
+		})
+	}
+
+	t.Run("time ordered identifiers", func(t *testing.T) {
+		for _, generate := range []CorrelationIDGenerator{UUIDv7, ULID} {
+			previous := generate()
+			time.Sleep(2 * time.Millisecond)
+
+			if next := generate(); next <= previous {
+				t.Fatalf("identifiers had to be sortable, got %s after %s", next, previous)
+			}
+		}
+	})
+}
+
+func TestValidateCorrelationID(t *testing.T) {
+	tests := map[string]struct {
+		id      string
+		isValid bool
+	}{
+		"uuid":              {id: UUIDv4(), isValid: true},
+		"dotted identifier": {id: "svc:request_1.retry-2", isValid: true},
+		"empty identifier":  {id: ""},
+		"oversized":         {id: strings.Repeat("a", MaxCorrelationIDLength+1)},
+		"header injection":  {id: "id\r\nX-Admin: true"},
+		"non ascii":         {id: "identificación"},
+		"maximum length":    {id: strings.Repeat("a", MaxCorrelationIDLength), isValid: true},
+		"spaces and commas": {id: "a b,c"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCorrelationID(test.id)
+			if test.isValid && err != nil {
+				t.Fatalf("unexpected error, got %s", err)
+			}
+			if !test.isValid && !errors.Is(err, ErrInvalidCorrelationID) {
+				t.Fatalf("unexpected error, got %v, want %s", err, ErrInvalidCorrelationID)
+			}
+		})
+	}
+}
+
+func TestEnsureCorrelationID(t *testing.T) {
+	t.Run("keeping a valid correlation ID", func(t *testing.T) {
+		ctx := SetDynamicAttributes(context.Background(), "correlation_id", true)
+		ctx = EnsureCorrelationID(ctx)
+
+		if got := CorrelationID(ctx).String(); got != "correlation_id" {
+			t.Fatalf("unexpected correlation ID, got %s, want correlation_id", got)
+		}
+		if !IsDryRun(ctx).Bool() {
+			t.Fatal("the other attributes had to be kept")
+		}
+	})
+
+	t.Run("replacing a missing or invalid correlation ID", func(t *testing.T) {
+		SetCorrelationIDGenerator(func() string { return "generated" })
+		t.Cleanup(func() { SetCorrelationIDGenerator(UUIDv4) })
+
+		for _, ctx := range []context.Context{
+			context.Background(),
+			SetDynamicAttributes(context.Background(), "", false),
+			SetDynamicAttributes(context.Background(), "not valid", false),
+		} {
+			if got := CorrelationID(EnsureCorrelationID(ctx)).String(); got != "generated" {
+				t.Fatalf("unexpected correlation ID, got %s, want generated", got)
+			}
+		}
+	})
+}
@@ -0,0 +1,483 @@
+package kv
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	_ json.Marshaler             = Val{}
+	_ json.Unmarshaler           = &Val{}
+	_ encoding.TextMarshaler     = Val{}
+	_ encoding.BinaryMarshaler   = Val{}
+	_ encoding.BinaryUnmarshaler = &Val{}
+
+	_ json.Marshaler             = Pair{}
+	_ json.Unmarshaler           = &Pair{}
+	_ encoding.TextMarshaler     = Pair{}
+	_ encoding.BinaryMarshaler   = Pair{}
+	_ encoding.BinaryUnmarshaler = &Pair{}
+)
+
+// obfuscatedFlag marks the encoded kind of obfuscated values.
+const obfuscatedFlag = 0x80
+
+var errTruncated = errors.New("kv: truncated binary encoding")
+
+type encodedVal struct {
+	Kind         string          `json:"kind"`
+	Value        json.RawMessage `json:"value,omitempty"`
+	IsObfuscated bool            `json:"obfuscated,omitempty"`
+}
+
+type encodedPair struct {
+	Key string `json:"key"`
+	encodedVal
+}
+
+// MarshalJSON encodes the value along with its kind, so that the type is
+// kept when decoding it. Numeric values are widened to their 64 bits kind.
+// Obfuscated values are encoded using their obfuscated value, meaning that
+// the original value is never included.
+func (v Val) MarshalJSON() ([]byte, error) {
+	enc, err := v.encoded()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a value encoded with MarshalJSON. Values of kind
+// any are decoded as encoding/json does when decoding into an interface.
+func (v *Val) UnmarshalJSON(data []byte) error {
+	var enc encodedVal
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return fmt.Errorf("kv: decoding value: %w", err)
+	}
+
+	return v.decode(enc)
+}
+
+// MarshalJSON encodes the pair key and value, as Val.MarshalJSON does.
+func (p Pair) MarshalJSON() ([]byte, error) {
+	enc, err := p.encoded()
+	if err != nil {
+		return nil, fmt.Errorf("kv: encoding %s: %w", p.key, err)
+	}
+
+	return json.Marshal(encodedPair{Key: p.key, encodedVal: enc})
+}
+
+// UnmarshalJSON decodes a pair encoded with MarshalJSON.
+func (p *Pair) UnmarshalJSON(data []byte) error {
+	var enc encodedPair
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return fmt.Errorf("kv: decoding pair: %w", err)
+	}
+
+	p.key = enc.Key
+	return p.Val.decode(enc.encodedVal)
+}
+
+// MarshalText provides a human readable representation of the value.
+// Strings are kept as they are, times use RFC 3339, durations use their
+// string form and bytes are base64 encoded. Other values are json encoded.
+// Obfuscated values are represented by their obfuscated value.
+func (v Val) MarshalText() ([]byte, error) {
+	if v.isObfuscated {
+		return []byte(v.obfuscated), nil
+	}
+
+	switch raw := v.raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(raw), nil
+	case time.Time:
+		return raw.MarshalText()
+	case time.Duration:
+		return []byte(raw.String()), nil
+	case []byte:
+		return []byte(base64.StdEncoding.EncodeToString(raw)), nil
+	default:
+		return json.Marshal(raw)
+	}
+}
+
+// MarshalText provides a human readable representation of the pair, in the
+// key=value form. The value is represented as Val.MarshalText does.
+func (p Pair) MarshalText() ([]byte, error) {
+	text, err := p.Val.MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("kv: encoding %s: %w", p.key, err)
+	}
+
+	return append([]byte(p.key+"="), text...), nil
+}
+
+// MarshalBinary encodes the value in a compact binary form, keeping its
+// type as MarshalJSON does. Obfuscated values are encoded using their
+// obfuscated value, meaning that the original value is never included.
+func (v Val) MarshalBinary() ([]byte, error) {
+	return v.appendBinary(nil)
+}
+
+// UnmarshalBinary decodes a value encoded with MarshalBinary.
+func (v *Val) UnmarshalBinary(data []byte) error {
+	rest, err := v.readBinary(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("kv: %d unexpected trailing bytes", len(rest))
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the pair key and value, as Val.MarshalBinary does.
+func (p Pair) MarshalBinary() ([]byte, error) {
+	return p.appendBinary(nil)
+}
+
+// UnmarshalBinary decodes a pair encoded with MarshalBinary.
+func (p *Pair) UnmarshalBinary(data []byte) error {
+	rest, err := p.readBinary(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("kv: %d unexpected trailing bytes", len(rest))
+	}
+
+	return nil
+}
+
+// EncodePairs encodes the given pairs in a compact binary form, as
+// Pair.MarshalBinary does.
+func EncodePairs(pairs Pairs) ([]byte, error) {
+	data := binary.AppendUvarint(nil, uint64(len(pairs)))
+	for _, pair := range pairs {
+		var err error
+		if data, err = pair.appendBinary(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// DecodePairs decodes the pairs encoded with EncodePairs.
+func DecodePairs(data []byte) (Pairs, error) {
+	n, data, err := readUvarint(data)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(data)) {
+		return nil, errTruncated
+	}
+
+	pairs := make(Pairs, n)
+	for i := range pairs {
+		if data, err = pairs[i].readBinary(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) > 0 {
+		return nil, fmt.Errorf("kv: %d unexpected trailing bytes", len(data))
+	}
+
+	return pairs, nil
+}
+
+// encoded provides the json representation of the value.
+func (v Val) encoded() (encodedVal, error) {
+	if v.isObfuscated {
+		js, _ := json.Marshal(v.obfuscated)
+		return encodedVal{Kind: KindString.String(), Value: js, IsObfuscated: true}, nil
+	}
+
+	kind := v.Kind()
+	if kind == KindNil {
+		return encodedVal{Kind: kind.String()}, nil
+	}
+
+	js, err := json.Marshal(v.canonical(kind))
+	if err != nil {
+		return encodedVal{}, err
+	}
+
+	return encodedVal{Kind: kind.String(), Value: js}, nil
+}
+
+func (v *Val) decode(enc encodedVal) error {
+	kind, exists := kindFromName(enc.Kind)
+	if !exists {
+		return fmt.Errorf("kv: unknown kind %s", enc.Kind)
+	}
+
+	if enc.IsObfuscated {
+		var obfuscated string
+		if err := json.Unmarshal(enc.Value, &obfuscated); err != nil {
+			return fmt.Errorf("kv: decoding obfuscated value: %w", err)
+		}
+
+		*v = Val{isObfuscated: true, obfuscated: obfuscated}
+		return nil
+	}
+
+	if kind == KindNil {
+		*v = Val{}
+		return nil
+	}
+
+	var (
+		raw interface{}
+		err error
+	)
+	switch kind {
+	case KindBool:
+		raw, err = unmarshalAs[bool](enc.Value)
+	case KindInt64:
+		raw, err = unmarshalAs[int64](enc.Value)
+	case KindUint64:
+		raw, err = unmarshalAs[uint64](enc.Value)
+	case KindFloat64:
+		raw, err = unmarshalAs[float64](enc.Value)
+	case KindString:
+		raw, err = unmarshalAs[string](enc.Value)
+	case KindDuration:
+		raw, err = unmarshalAs[time.Duration](enc.Value)
+	case KindTime:
+		raw, err = unmarshalAs[time.Time](enc.Value)
+	case KindBytes:
+		raw, err = unmarshalAs[[]byte](enc.Value)
+	case KindStringSlice:
+		raw, err = unmarshalAs[[]string](enc.Value)
+	default:
+		raw, err = unmarshalAs[interface{}](enc.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("kv: decoding %s value: %w", kind, err)
+	}
+
+	*v = Val{raw: raw}
+	return nil
+}
+
+func unmarshalAs[T any](data []byte) (interface{}, error) {
+	var val T
+	err := json.Unmarshal(data, &val)
+
+	return val, err
+}
+
+// canonical provides the raw value using the widest type of its kind.
+func (v Val) canonical(kind Kind) interface{} {
+	switch kind {
+	case KindInt64:
+		return v.Int64()
+	case KindUint64:
+		return v.Uint64()
+	case KindFloat64:
+		return v.Float64()
+	default:
+		return v.raw
+	}
+}
+
+func (v Val) appendBinary(data []byte) ([]byte, error) {
+	if v.isObfuscated {
+		data = append(data, byte(KindString)|obfuscatedFlag)
+		return appendString(data, v.obfuscated), nil
+	}
+
+	kind := v.Kind()
+	data = append(data, byte(kind))
+
+	switch kind {
+	case KindNil:
+		return data, nil
+	case KindBool:
+		if v.Bool() {
+			return append(data, 1), nil
+		}
+		return append(data, 0), nil
+	case KindInt64, KindDuration:
+		return binary.AppendVarint(data, v.Int64()), nil
+	case KindUint64:
+		return binary.AppendUvarint(data, v.Uint64()), nil
+	case KindFloat64:
+		return binary.BigEndian.AppendUint64(data, math.Float64bits(v.Float64())), nil
+	case KindString:
+		return appendString(data, v.String()), nil
+	case KindTime:
+		t, err := v.Time().MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return appendString(data, string(t)), nil
+	case KindBytes:
+		return appendString(data, string(v.Bytes())), nil
+	case KindStringSlice:
+		strs := v.StringSlice()
+		data = binary.AppendUvarint(data, uint64(len(strs)))
+		for _, s := range strs {
+			data = appendString(data, s)
+		}
+		return data, nil
+	default:
+		js, err := json.Marshal(v.raw)
+		if err != nil {
+			return nil, err
+		}
+		return appendString(data, string(js)), nil
+	}
+}
+
+func (v *Val) readBinary(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errTruncated
+	}
+
+	kind, isObfuscated := Kind(data[0]&^obfuscatedFlag), data[0]&obfuscatedFlag != 0
+	data = data[1:]
+
+	if isObfuscated {
+		obfuscated, rest, err := readString(data)
+		if err != nil {
+			return nil, err
+		}
+
+		*v = Val{isObfuscated: true, obfuscated: obfuscated}
+		return rest, nil
+	}
+
+	var (
+		raw interface{}
+		err error
+	)
+	switch kind {
+	case KindNil:
+	case KindBool:
+		if len(data) == 0 {
+			return nil, errTruncated
+		}
+		raw, data = data[0] == 1, data[1:]
+	case KindInt64, KindDuration:
+		i, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		raw, data = i, data[n:]
+		if kind == KindDuration {
+			raw = time.Duration(i)
+		}
+	case KindUint64:
+		raw, data, err = readUvarint(data)
+	case KindFloat64:
+		if len(data) < 8 {
+			return nil, errTruncated
+		}
+		raw, data = math.Float64frombits(binary.BigEndian.Uint64(data)), data[8:]
+	case KindString:
+		raw, data, err = readString(data)
+	case KindTime:
+		var s string
+		if s, data, err = readString(data); err == nil {
+			var t time.Time
+			err = t.UnmarshalBinary([]byte(s))
+			raw = t
+		}
+	case KindBytes:
+		var s string
+		s, data, err = readString(data)
+		raw = []byte(s)
+	case KindStringSlice:
+		raw, data, err = readStrings(data)
+	case KindAny:
+		var s string
+		if s, data, err = readString(data); err == nil {
+			raw, err = unmarshalAs[interface{}]([]byte(s))
+		}
+	default:
+		return nil, fmt.Errorf("kv: unknown kind %d", kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kv: decoding %s value: %w", kind, err)
+	}
+
+	*v = Val{raw: raw}
+	return data, nil
+}
+
+func (p Pair) appendBinary(data []byte) ([]byte, error) {
+	data, err := p.Val.appendBinary(appendString(data, p.key))
+	if err != nil {
+		return nil, fmt.Errorf("kv: encoding %s: %w", p.key, err)
+	}
+
+	return data, nil
+}
+
+func (p *Pair) readBinary(data []byte) ([]byte, error) {
+	key, data, err := readString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.key = key
+	return p.Val.readBinary(data)
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+func readUvarint(data []byte) (uint64, []byte, error) {
+	u, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errTruncated
+	}
+
+	return u, data[n:], nil
+}
+
+func readString(data []byte) (string, []byte, error) {
+	size, data, err := readUvarint(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if size > uint64(len(data)) {
+		return "", nil, errTruncated
+	}
+
+	return string(data[:size]), data[size:], nil
+}
+
+func readStrings(data []byte) ([]string, []byte, error) {
+	n, data, err := readUvarint(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n > uint64(len(data)) {
+		return nil, nil, errTruncated
+	}
+
+	strs := make([]string, n)
+	for i := range strs {
+		if strs[i], data, err = readString(data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return strs, data, nil
+}
@@ -0,0 +1,210 @@
+package kv
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var encodedTime = time.Date(2022, time.March, 4, 5, 6, 7, 8, time.UTC)
+
+func encodingPairs() Pairs {
+	return Pairs{
+		New("nil", nil),
+		New("bool", true),
+		New("int", -42),
+		New("uint", uint8(42)),
+		New("float", 4.2),
+		New("string", "value, with \"symbols\""),
+		New("duration", 3*time.Second),
+		New("time", encodedTime),
+		New("bytes", []byte{0, 1, 2}),
+		New("strings", []string{"one", "two"}),
+		New("any", map[string]interface{}{"one": 1.0}),
+	}
+}
+
+// decodedPairs are the pairs expected after decoding encodingPairs.
+func decodedPairs() Pairs {
+	return Pairs{
+		New("nil", nil),
+		New("bool", true),
+		New("int", int64(-42)),
+		New("uint", uint64(42)),
+		New("float", 4.2),
+		New("string", "value, with \"symbols\""),
+		New("duration", 3*time.Second),
+		New("time", encodedTime),
+		New("bytes", []byte{0, 1, 2}),
+		New("strings", []string{"one", "two"}),
+		New("any", map[string]interface{}{"one": 1.0}),
+	}
+}
+
+var pairComparer = cmp.Comparer(func(a, b Pair) bool {
+	return a.key == b.key &&
+		a.isObfuscated == b.isObfuscated &&
+		a.obfuscated == b.obfuscated &&
+		cmp.Equal(a.raw, b.raw)
+})
+
+func TestJSONEncoding(t *testing.T) {
+	t.Run("keeping types", func(t *testing.T) {
+		js, err := json.Marshal(encodingPairs())
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		var got Pairs
+		if err := json.Unmarshal(js, &got); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if diff := cmp.Diff(decodedPairs(), got, pairComparer); diff != "" {
+			t.Fatalf("unexpected pairs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("encoding a value", func(t *testing.T) {
+		js, err := json.Marshal(Value(3 * time.Second))
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if want := `{"kind":"duration","value":3000000000}`; string(js) != want {
+			t.Fatalf("unexpected encoding, got %s, want %s", js, want)
+		}
+
+		var got Val
+		if err := json.Unmarshal(js, &got); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+		if got.Duration() != 3*time.Second {
+			t.Fatalf("unexpected value, got %s", got.Duration())
+		}
+	})
+
+	t.Run("decoding invalid values", func(t *testing.T) {
+		for _, js := range []string{
+			`{"key":"k","kind":"unknown","value":1}`,
+			`{"key":"k","kind":"bool","value":"true"}`,
+			`[]`,
+		} {
+			var p Pair
+			if err := json.Unmarshal([]byte(js), &p); err == nil {
+				t.Fatalf("an error was expected for %s", js)
+			}
+		}
+	})
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	t.Run("keeping types", func(t *testing.T) {
+		data, err := EncodePairs(encodingPairs())
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		got, err := DecodePairs(data)
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if diff := cmp.Diff(decodedPairs(), got, pairComparer); diff != "" {
+			t.Fatalf("unexpected pairs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("encoding a single pair", func(t *testing.T) {
+		data, err := New("int", 42).MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		var got Pair
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+		if got.Name() != "int" || got.Int() != 42 {
+			t.Fatalf("unexpected pair, got %s=%v", got.Name(), got.Value())
+		}
+	})
+
+	t.Run("decoding truncated data", func(t *testing.T) {
+		data, _ := EncodePairs(encodingPairs())
+
+		for i := 0; i < len(data); i++ {
+			if _, err := DecodePairs(data[:i]); err == nil {
+				t.Fatalf("an error was expected when truncating at %d", i)
+			}
+		}
+	})
+}
+
+func TestTextEncoding(t *testing.T) {
+	tests := map[string]struct {
+		pair Pair
+		want string
+	}{
+		"string":     {pair: New("k", "a value"), want: "k=a value"},
+		"integer":    {pair: New("k", 42), want: "k=42"},
+		"duration":   {pair: New("k", 3*time.Second), want: "k=3s"},
+		"time":       {pair: New("k", encodedTime), want: "k=2022-03-04T05:06:07.000000008Z"},
+		"bytes":      {pair: New("k", []byte("abc")), want: "k=YWJj"},
+		"slice":      {pair: New("k", []string{"a", "b"}), want: `k=["a","b"]`},
+		"nil":        {pair: New("k", nil), want: "k="},
+		"obfuscated": {pair: NewObfuscated("k", "secret"), want: "k=" + redactedValue},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			text, err := test.pair.MarshalText()
+			if err != nil {
+				t.Fatalf("unexpected error, got %s", err)
+			}
+
+			if got := string(text); got != test.want {
+				t.Fatalf("unexpected text, got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodingObfuscatedPairs(t *testing.T) {
+	pair := NewObfuscatedWith("card", "4111111111111111", MaskAllButLast(4))
+
+	js, err := json.Marshal(pair)
+	if err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+	data, err := pair.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+
+	for _, encoded := range []string{string(js), string(data)} {
+		if strings.Contains(encoded, "4111111111111111") {
+			t.Fatalf("obfuscated values can't be revealed, got %q", encoded)
+		}
+	}
+
+	var fromJSON, fromBinary Pair
+	if err := json.Unmarshal(js, &fromJSON); err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+	if err := fromBinary.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+
+	for _, got := range []Pair{fromJSON, fromBinary} {
+		if !got.IsObfuscated() || got.String() != pair.String() {
+			t.Fatalf("the obfuscated value had to be kept, got %s", got.String())
+		}
+		if raw := Reveal(got, "testing"); raw != nil {
+			t.Fatalf("the original value can't be decoded, got %v", raw)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+)
+
+const (
+	defaultBuildIDEnv     = "BUILD_ID"
+	defaultServiceHostEnv = "HOSTNAME"
+	defaultServiceNameEnv = "SERVICE_NAME"
+)
+
+var (
+	goVersionKey   = newBuiltInKey[string]("svc.go_version")
+	pidKey         = newBuiltInKey[int]("svc.pid")
+	containerIDKey = newBuiltInKey[string]("svc.container_id")
+
+	// Replaced during tests.
+	readBuildInfo = debug.ReadBuildInfo
+	cgroupFiles   = []cgroupFile{
+		{"/proc/self/cgroup", cgroupIDExpr},
+		{"/proc/self/mountinfo", mountinfoIDExpr},
+	}
+
+	cgroupIDExpr = regexp.MustCompile(`([0-9a-f]{64})`)
+
+	// Mount points also refer to image layers and, on hosts, to other
+	// containers. Only the ones under the container directory are considered.
+	mountinfoIDExpr = regexp.MustCompile(`(?:/containers/|docker-)([0-9a-f]{64})(?:/|\.scope)`)
+)
+
+// cgroupFile indicates where to look for the container ID and how to find it.
+type cgroupFile struct {
+	name string
+	expr *regexp.Regexp
+}
+
+// EnvOption allows to tweak how the environment attributes are found.
+type EnvOption func(*environment)
+
+// WithBuildIDEnv indicates the environment variable that holds the build ID.
+// Defaults to BUILD_ID.
+func WithBuildIDEnv(name string) EnvOption {
+	return func(env *environment) {
+		env.buildIDEnv = name
+	}
+}
+
+// WithServiceHostEnv indicates the environment variable that holds the
+// service host. Defaults to HOSTNAME.
+func WithServiceHostEnv(name string) EnvOption {
+	return func(env *environment) {
+		env.serviceHostEnv = name
+	}
+}
+
+// WithServiceNameEnv indicates the environment variable that holds the
+// service name. Defaults to SERVICE_NAME.
+func WithServiceNameEnv(name string) EnvOption {
+	return func(env *environment) {
+		env.serviceNameEnv = name
+	}
+}
+
+// WithServiceName indicates the service name to use when the environment
+// doesn't provide one.
+func WithServiceName(name string) EnvOption {
+	return func(env *environment) {
+		env.serviceName = name
+	}
+}
+
+// WithGoVersion includes the Go version the program was built with.
+func WithGoVersion() EnvOption {
+	return func(env *environment) {
+		env.hasGoVersion = true
+	}
+}
+
+// WithPID includes the program process ID.
+func WithPID() EnvOption {
+	return func(env *environment) {
+		env.hasPID = true
+	}
+}
+
+// WithContainerID includes the ID of the container the program runs in, as
+// found in the cgroup files. It's omitted when not running in a container.
+func WithContainerID() EnvOption {
+	return func(env *environment) {
+		env.hasContainerID = true
+	}
+}
+
+type environment struct {
+	buildIDEnv     string
+	serviceHostEnv string
+	serviceNameEnv string
+	serviceName    string
+
+	hasGoVersion   bool
+	hasPID         bool
+	hasContainerID bool
+}
+
+// SetEnvironmentAttributes sets program attributes into the given context,
+// like SetStaticAttributes does, finding them in the build and runtime
+// environment. Environment variables take precedence. Otherwise, the build
+// ID is the VCS revision or the module version, the service host is the
+// host name and the service name is the main package name.
+func SetEnvironmentAttributes(ctx context.Context, opts ...EnvOption) context.Context {
+	env := &environment{
+		buildIDEnv:     defaultBuildIDEnv,
+		serviceHostEnv: defaultServiceHostEnv,
+		serviceNameEnv: defaultServiceNameEnv,
+	}
+	for _, opt := range opts {
+		opt(env)
+	}
+
+	buildID, serviceName := fromBuildInfo()
+	if env.serviceName != "" {
+		serviceName = env.serviceName
+	}
+	serviceHost, _ := os.Hostname()
+
+	values := map[string]interface{}{
+		buildIDKey.name:     fromEnv(env.buildIDEnv, buildID),
+		serviceHostKey.name: fromEnv(env.serviceHostEnv, serviceHost),
+		serviceNameKey.name: fromEnv(env.serviceNameEnv, serviceName),
+	}
+
+	if env.hasGoVersion {
+		values[goVersionKey.name] = runtime.Version()
+	}
+	if env.hasPID {
+		values[pidKey.name] = os.Getpid()
+	}
+	if env.hasContainerID {
+		if id := containerID(); id != "" {
+			values[containerIDKey.name] = id
+		}
+	}
+
+	return setAttributes(ctx, values)
+}
+
+func fromEnv(name, fallback string) string {
+	if val, exists := os.LookupEnv(name); exists && val != "" {
+		return val
+	}
+
+	return fallback
+}
+
+// fromBuildInfo returns the build ID and the service name as found in the
+// build information, when available.
+func fromBuildInfo() (string, string) {
+	info, ok := readBuildInfo()
+	if !ok {
+		return "", ""
+	}
+
+	var serviceName string
+	if info.Path != "" {
+		serviceName = path.Base(info.Path)
+	}
+
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+
+	switch {
+	case revision != "" && modified == "true":
+		return revision + "-dirty", serviceName
+	case revision != "":
+		return revision, serviceName
+	case info.Main.Version != "(devel)":
+		return info.Main.Version, serviceName
+	default:
+		return "", serviceName
+	}
+}
+
+func containerID() string {
+	for _, file := range cgroupFiles {
+		f, err := os.Open(file.name)
+		if err != nil {
+			continue
+		}
+
+		id := containerIDFrom(f, file.expr)
+		_ = f.Close()
+
+		if id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// containerIDFrom finds a container ID in the given cgroup file contents,
+// as captured by the given expression.
+func containerIDFrom(r io.Reader, expr *regexp.Regexp) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if match := expr.FindStringSubmatch(scanner.Text()); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
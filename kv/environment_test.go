@@ -0,0 +1,204 @@
+package kv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+const containerIDFixture = "4e6e1b3d2f4b1d0c7a9f3e2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d"
+
+func TestEnvironmentAttributes(t *testing.T) {
+	stubBuildInfo(t, &debug.BuildInfo{
+		Path: "github.com/thisiserico/service/cmd/api",
+		Main: debug.Module{Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "a1b2c3"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	})
+
+	t.Run("finding attributes in the build information", func(t *testing.T) {
+		t.Setenv(defaultBuildIDEnv, "")
+		t.Setenv(defaultServiceNameEnv, "")
+		t.Setenv(defaultServiceHostEnv, "")
+
+		ctx := SetEnvironmentAttributes(context.Background())
+
+		host, _ := os.Hostname()
+		assertStaticAttributes(t, ctx, "a1b2c3", host, "api")
+	})
+
+	t.Run("finding attributes in environment variables", func(t *testing.T) {
+		t.Setenv(defaultBuildIDEnv, "build")
+		t.Setenv("TEST_SERVICE_HOST", "host")
+		t.Setenv("TEST_SERVICE_NAME", "name")
+
+		ctx := SetEnvironmentAttributes(
+			context.Background(),
+			WithServiceHostEnv("TEST_SERVICE_HOST"),
+			WithServiceNameEnv("TEST_SERVICE_NAME"),
+			WithServiceName("ignored"),
+		)
+
+		assertStaticAttributes(t, ctx, "build", "host", "name")
+	})
+
+	t.Run("providing a service name", func(t *testing.T) {
+		t.Setenv(defaultServiceNameEnv, "")
+
+		ctx := SetEnvironmentAttributes(context.Background(), WithServiceName("name"))
+
+		if got := serviceNameAttribute(ctx); got != "name" {
+			t.Fatalf("unexpected service name, got %s, want name", got)
+		}
+	})
+
+	t.Run("including runtime extras", func(t *testing.T) {
+		stubCgroupFile(t, "0::/system.slice/docker-"+containerIDFixture+".scope\n")
+
+		ctx := SetEnvironmentAttributes(context.Background(), WithGoVersion(), WithPID(), WithContainerID())
+
+		if got, _ := goVersionKey.Get(ctx); got != runtime.Version() {
+			t.Errorf("unexpected go version, got %s, want %s", got, runtime.Version())
+		}
+		if got, _ := pidKey.Get(ctx); got != os.Getpid() {
+			t.Errorf("unexpected pid, got %d, want %d", got, os.Getpid())
+		}
+		if got, _ := containerIDKey.Get(ctx); got != containerIDFixture {
+			t.Errorf("unexpected container ID, got %s, want %s", got, containerIDFixture)
+		}
+	})
+
+	t.Run("omitting runtime extras by default", func(t *testing.T) {
+		ctx := SetEnvironmentAttributes(context.Background())
+
+		for _, name := range []string{goVersionKey.Name(), pidKey.Name(), containerIDKey.Name()} {
+			if _, exists := attributesFrom(ctx)[name]; exists {
+				t.Errorf("unexpected attribute %s", name)
+			}
+		}
+	})
+}
+
+func TestBuildIDFromBuildInfo(t *testing.T) {
+	tests := map[string]struct {
+		info *debug.BuildInfo
+		want string
+	}{
+		"vcs revision": {
+			info: &debug.BuildInfo{Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "a1b2c3"}}},
+			want: "a1b2c3",
+		},
+		"modified vcs revision": {
+			info: &debug.BuildInfo{Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "a1b2c3"},
+				{Key: "vcs.modified", Value: "true"},
+			}},
+			want: "a1b2c3-dirty",
+		},
+		"module version": {
+			info: &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}},
+			want: "v1.2.3",
+		},
+		"development build": {
+			info: &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}},
+			want: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stubBuildInfo(t, test.info)
+
+			if got, _ := fromBuildInfo(); got != test.want {
+				t.Fatalf("unexpected build ID, got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestContainerIDFrom(t *testing.T) {
+	layerID := strings.Repeat("ab", 32)
+
+	tests := map[string]struct {
+		contents string
+		expr     *regexp.Regexp
+		want     string
+	}{
+		"cgroup v1": {
+			contents: "12:pids:/docker/" + containerIDFixture + "\n11:memory:/docker/" + containerIDFixture + "\n",
+			expr:     cgroupIDExpr,
+			want:     containerIDFixture,
+		},
+		"cgroup v2 mountinfo": {
+			contents: "1 0 0:1 / / rw - overlay overlay rw,lowerdir=/var/lib/docker/overlay2/l/ABC,upperdir=/var/lib/docker/overlay2/" + layerID + "/diff,workdir=/var/lib/docker/overlay2/" + layerID + "/work\n" +
+				"2 1 0:2 /var/lib/docker/containers/" + containerIDFixture + "/hostname /etc/hostname rw\n",
+			expr: mountinfoIDExpr,
+			want: containerIDFixture,
+		},
+		"host mountinfo": {
+			contents: "1 0 0:1 /var/lib/docker/overlay2/" + layerID + "/merged /var/lib/docker/overlay2/" + layerID + "/merged rw - overlay overlay rw\n",
+			expr:     mountinfoIDExpr,
+			want:     "",
+		},
+		"not a container": {
+			contents: "0::/user.slice/user-1000.slice/session-1.scope\n",
+			expr:     cgroupIDExpr,
+			want:     "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := containerIDFrom(strings.NewReader(test.contents), test.expr); got != test.want {
+				t.Fatalf("unexpected container ID, got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func stubBuildInfo(t *testing.T, info *debug.BuildInfo) {
+	t.Helper()
+
+	original := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return info, true }
+	t.Cleanup(func() { readBuildInfo = original })
+}
+
+func stubCgroupFile(t *testing.T, contents string) {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "cgroup")
+	if err := os.WriteFile(name, []byte(contents), 0o600); err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+
+	original := cgroupFiles
+	cgroupFiles = []cgroupFile{{name, cgroupIDExpr}}
+	t.Cleanup(func() { cgroupFiles = original })
+}
+
+func assertStaticAttributes(t *testing.T, ctx context.Context, buildID, serviceHost, serviceName string) {
+	t.Helper()
+
+	if got, _ := buildIDKey.Get(ctx); got != buildID {
+		t.Errorf("unexpected build ID, got %s, want %s", got, buildID)
+	}
+	if got, _ := serviceHostKey.Get(ctx); got != serviceHost {
+		t.Errorf("unexpected service host, got %s, want %s", got, serviceHost)
+	}
+	if got := serviceNameAttribute(ctx); got != serviceName {
+		t.Errorf("unexpected service name, got %s, want %s", got, serviceName)
+	}
+}
+
+func serviceNameAttribute(ctx context.Context) string {
+	name, _ := serviceNameKey.Get(ctx)
+	return name
+}
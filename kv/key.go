@@ -0,0 +1,153 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+var (
+	registryLock sync.RWMutex
+	registry     []registeredKey
+	registered   = make(map[string]bool)
+)
+
+// registeredKey lets the registry work with keys of any type.
+type registeredKey interface {
+	Name() string
+	pair(attributes) Pair
+	decode(context.Context, []byte) (context.Context, error)
+	decodeText(context.Context, string) (context.Context, error)
+	isBuiltIn() bool
+}
+
+// Key identifies a context attribute holding values of the given type.
+type Key[T any] struct {
+	name    string
+	builtIn bool
+}
+
+// NewKey creates and registers a new key using the given name. Registered
+// keys are provided by AllAttributes and CustomAttributes, meaning that their
+// attributes will be part of log lines, telemetry data and events. It panics
+// if a key with the same name already exists, so keys are expected to be
+// created when initializing the program.
+func NewKey[T any](name string) Key[T] {
+	return register(Key[T]{name: name})
+}
+
+func newBuiltInKey[T any](name string) Key[T] {
+	return register(Key[T]{name: name, builtIn: true})
+}
+
+func register[T any](k Key[T]) Key[T] {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if registered[k.name] {
+		panic(fmt.Sprintf("kv: key %s already exists", k.name))
+	}
+
+	registered[k.name] = true
+	registry = append(registry, k)
+
+	return k
+}
+
+// Name returns the key name.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Set returns a copy of the given context holding the given value.
+func (k Key[T]) Set(ctx context.Context, val T) context.Context {
+	return setAttributes(ctx, map[string]interface{}{k.name: val})
+}
+
+// Get returns the value held in the given context. It also returns a boolean
+// indicating whether the value exists.
+func (k Key[T]) Get(ctx context.Context) (T, bool) {
+	val, ok := attributesFrom(ctx)[k.name].(T)
+	return val, ok
+}
+
+// pair returns the attribute as a pair, having a nil value when the
+// attribute doesn't exist.
+func (k Key[T]) pair(attrs attributes) Pair {
+	return New(k.name, attrs[k.name])
+}
+
+func (k Key[T]) decode(ctx context.Context, data []byte) (context.Context, error) {
+	var val T
+	if err := json.Unmarshal(data, &val); err != nil {
+		return ctx, fmt.Errorf("kv: decoding key %s: %w", k.name, err)
+	}
+
+	return k.Set(ctx, val), nil
+}
+
+// decodeText works like decode, except that string values are expected
+// as they are, instead of json encoded.
+func (k Key[T]) decodeText(ctx context.Context, text string) (context.Context, error) {
+	var val T
+	if s, ok := any(&val).(*string); ok {
+		*s = text
+		return k.Set(ctx, val), nil
+	}
+
+	return k.decode(ctx, []byte(text))
+}
+
+func (k Key[T]) isBuiltIn() bool {
+	return k.builtIn
+}
+
+func registeredKeys() []registeredKey {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	return registry
+}
+
+func lookupKey(name string) (registeredKey, bool) {
+	for _, k := range registeredKeys() {
+		if k.Name() == name {
+			return k, true
+		}
+	}
+
+	return nil, false
+}
+
+// CustomAttributes returns the pairs for the attributes that exist in the
+// context, considering only the keys created by clients.
+func CustomAttributes(ctx context.Context) Pairs {
+	attrs := attributesFrom(ctx)
+
+	var pairs Pairs
+	for _, k := range registeredKeys() {
+		if k.isBuiltIn() {
+			continue
+		}
+
+		if pair := k.pair(attrs); pair.raw != nil {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+// SetEncodedAttribute sets into the given context the attribute that the
+// given json encoded value represents, as identified by the key name.
+// An error is returned when the key doesn't exist or the value can't be
+// decoded.
+func SetEncodedAttribute(ctx context.Context, name string, data []byte) (context.Context, error) {
+	k, exists := lookupKey(name)
+	if !exists {
+		return ctx, fmt.Errorf("kv: unknown key %s", name)
+	}
+
+	return k.decode(ctx, data)
+}
@@ -0,0 +1,79 @@
+package kv
+
+import (
+	"context"
+	"testing"
+)
+
+var (
+	tenantKey  = NewKey[string]("test.tenant")
+	retriesKey = NewKey[int]("test.retries")
+)
+
+func TestCustomKeys(t *testing.T) {
+	t.Run("setting and getting values", func(t *testing.T) {
+		ctx := tenantKey.Set(context.Background(), "tenant")
+
+		if got, ok := tenantKey.Get(ctx); !ok || got != "tenant" {
+			t.Fatalf("unexpected value, want tenant, got %s", got)
+		}
+		if got, ok := retriesKey.Get(ctx); ok || got != 0 {
+			t.Fatalf("no value was expected, got %d", got)
+		}
+	})
+
+	t.Run("providing the attributes", func(t *testing.T) {
+		ctx := SetDynamicAttributes(context.Background(), "correlation_id", false)
+		ctx = retriesKey.Set(ctx, 3)
+
+		var found bool
+		for _, attr := range AllAttributes(ctx) {
+			if attr.Name() == retriesKey.Name() {
+				found = attr.Int() == 3
+			}
+		}
+		if !found {
+			t.Fatal("the custom attribute had to be provided")
+		}
+
+		custom := CustomAttributes(ctx)
+		if len(custom) != 1 || custom[0].Name() != retriesKey.Name() {
+			t.Fatalf("only the existing custom attribute had to be provided, got %v", custom)
+		}
+	})
+
+	t.Run("creating an existing key", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("creating an existing key had to panic")
+			}
+		}()
+
+		NewKey[bool](tenantKey.Name())
+	})
+}
+
+func TestSettingEncodedAttributes(t *testing.T) {
+	t.Run("for a known key", func(t *testing.T) {
+		ctx, err := SetEncodedAttribute(context.Background(), retriesKey.Name(), []byte("3"))
+		if err != nil {
+			t.Fatalf("unexpected error, got %s", err)
+		}
+
+		if got, _ := retriesKey.Get(ctx); got != 3 {
+			t.Fatalf("unexpected value, want 3, got %d", got)
+		}
+	})
+
+	t.Run("for an unknown key", func(t *testing.T) {
+		if _, err := SetEncodedAttribute(context.Background(), "unknown", []byte("3")); err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+
+	t.Run("using an invalid value", func(t *testing.T) {
+		if _, err := SetEncodedAttribute(context.Background(), retriesKey.Name(), []byte(`"3"`)); err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package kv
+
+import "time"
+
+const (
+	// KindNil indicates that no value exists.
+	KindNil Kind = iota
+
+	// KindAny indicates a value of a type not listed below.
+	KindAny
+
+	// KindBool indicates a boolean value.
+	KindBool
+
+	// KindInt64 indicates a signed integer value of any size.
+	KindInt64
+
+	// KindUint64 indicates an unsigned integer value of any size.
+	KindUint64
+
+	// KindFloat64 indicates a float value of any size.
+	KindFloat64
+
+	// KindString indicates a string value.
+	KindString
+
+	// KindDuration indicates a time.Duration value.
+	KindDuration
+
+	// KindTime indicates a time.Time value.
+	KindTime
+
+	// KindBytes indicates a []byte value.
+	KindBytes
+
+	// KindStringSlice indicates a []string value.
+	KindStringSlice
+)
+
+var kindNames = [...]string{
+	KindNil:         "nil",
+	KindAny:         "any",
+	KindBool:        "bool",
+	KindInt64:       "int64",
+	KindUint64:      "uint64",
+	KindFloat64:     "float64",
+	KindString:      "string",
+	KindDuration:    "duration",
+	KindTime:        "time",
+	KindBytes:       "bytes",
+	KindStringSlice: "string_slice",
+}
+
+// Kind indicates the type of a value.
+type Kind int
+
+// String returns the kind name.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return kindNames[KindAny]
+	}
+
+	return kindNames[k]
+}
+
+func kindOf(raw interface{}) Kind {
+	switch raw.(type) {
+	case nil:
+		return KindNil
+	case bool:
+		return KindBool
+	case time.Duration:
+		return KindDuration
+	case int, int8, int16, int32, int64:
+		return KindInt64
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		return KindUint64
+	case float32, float64:
+		return KindFloat64
+	case string:
+		return KindString
+	case time.Time:
+		return KindTime
+	case []byte:
+		return KindBytes
+	case []string:
+		return KindStringSlice
+	default:
+		return KindAny
+	}
+}
+
+func kindFromName(name string) (Kind, bool) {
+	for k, kindName := range kindNames {
+		if kindName == name {
+			return Kind(k), true
+		}
+	}
+
+	return KindNil, false
+}
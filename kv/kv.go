@@ -4,14 +4,20 @@
 // is also provided.
 package kv
 
-const redactedValue = "redacted"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
 // Val encapsulates the given value.
-// Keeping it encapsulated allows to work with obfuscated pairs.
+// Keeping it encapsulated allows to work with obfuscated pairs, for which
+// typed accessors provide zero values.
 // A value can also be used on its own.
 type Val struct {
 	raw          interface{}
 	isObfuscated bool
+	obfuscated   string
 }
 
 // Pair encapsulates a key-value representation.
@@ -30,13 +36,23 @@ func New(key string, val interface{}) Pair {
 }
 
 // NewObfuscated generates a new Pair using the given key. The value, however,
-// will be obfuscated. This prevents situations where a value is not supposed
-// to be reported to other components. Only strings are supported at this time.
+// will be fully redacted. This prevents situations where a value is not
+// supposed to be reported to other components. The original value can only
+// be accessed by using Reveal.
 func NewObfuscated(key string, val interface{}) Pair {
+	return NewObfuscatedWith(key, val, Redact)
+}
+
+// NewObfuscatedWith generates a new Pair using the given key. The value will
+// be reported as the given obfuscator indicates. The original value can only
+// be accessed by using Reveal.
+func NewObfuscatedWith(key string, val interface{}, obfuscate Obfuscator) Pair {
 	return Pair{
 		key: key,
 		Val: Val{
+			raw:          val,
 			isObfuscated: true,
+			obfuscated:   obfuscate(val),
 		},
 	}
 }
@@ -52,20 +68,25 @@ func Value(v interface{}) Val {
 }
 
 // Value returns the raw value in its original form. If the value is obfuscated,
-// a redacted value is provided instead.
+// the obfuscated value is provided instead.
 func (v Val) Value() interface{} {
 	if v.isObfuscated {
-		return redactedValue
+		return v.obfuscated
 	}
 
 	return v.raw
 }
 
+// IsObfuscated indicates whether the value is obfuscated.
+func (v Val) IsObfuscated() bool {
+	return v.isObfuscated
+}
+
 // String returns the raw string value, or empty string if one doesn't exist.
-// If the value is obfuscated, a redacted value is provided instead.
+// If the value is obfuscated, the obfuscated value is provided instead.
 func (v Val) String() string {
 	if v.isObfuscated {
-		return redactedValue
+		return v.obfuscated
 	}
 
 	s, ok := v.raw.(string)
@@ -76,27 +97,222 @@ func (v Val) String() string {
 	return s
 }
 
-// Int returns the raw integer value, or 0 if one doesn't exist.
-func (v Val) Int() int {
-	i, ok := v.raw.(int)
-	if ok {
-		return i
+// GoString implements fmt.GoStringer, used by the %#v verb. Obfuscated values
+// only provide their obfuscated form.
+func (v Val) GoString() string {
+	if v.isObfuscated {
+		return fmt.Sprintf("kv.Val{obfuscated: %q}", v.obfuscated)
+	}
+
+	return fmt.Sprintf("kv.Val{raw: %#v}", v.raw)
+}
+
+// GoString implements fmt.GoStringer, used by the %#v verb. Obfuscated values
+// only provide their obfuscated form.
+func (p Pair) GoString() string {
+	if p.isObfuscated {
+		return fmt.Sprintf("kv.Pair{key: %q, obfuscated: %q}", p.key, p.obfuscated)
 	}
 
-	f, ok := v.raw.(float64)
-	if ok {
-		return int(f)
+	return fmt.Sprintf("kv.Pair{key: %q, raw: %#v}", p.key, p.raw)
+}
+
+// Int returns the raw integer value, or 0 if one doesn't exist. Any numeric
+// value that fits is converted.
+func (v Val) Int() int {
+	i, ok := toInt64(v.unobfuscated())
+	if !ok || int64(int(i)) != i {
+		return 0
 	}
 
-	return 0
+	return int(i)
+}
+
+// Int64 returns the raw integer value, or 0 if one doesn't exist. Any numeric
+// value that fits is converted, floats are truncated.
+func (v Val) Int64() int64 {
+	i, _ := toInt64(v.unobfuscated())
+	return i
+}
+
+// Uint64 returns the raw unsigned integer value, or 0 if one doesn't exist.
+// Any non-negative numeric value is converted, floats are truncated.
+func (v Val) Uint64() uint64 {
+	u, _ := toUint64(v.unobfuscated())
+	return u
+}
+
+// Float64 returns the raw float value, or 0 if one doesn't exist. Any numeric
+// value is converted.
+func (v Val) Float64() float64 {
+	f, _ := toFloat64(v.unobfuscated())
+	return f
 }
 
 // Bool returns the raw boolean value, or false if one doesn't exist.
 func (v Val) Bool() bool {
-	b, ok := v.raw.(bool)
+	b, ok := v.unobfuscated().(bool)
 	if !ok {
 		return false
 	}
 
 	return b
 }
+
+// Duration returns the raw duration value, or 0 if one doesn't exist.
+// Integer values are considered nanoseconds.
+func (v Val) Duration() time.Duration {
+	if d, ok := v.unobfuscated().(time.Duration); ok {
+		return d
+	}
+
+	i, _ := toInt64(v.unobfuscated())
+	return time.Duration(i)
+}
+
+// Time returns the raw time value, or the zero time if one doesn't exist.
+func (v Val) Time() time.Time {
+	t, ok := v.unobfuscated().(time.Time)
+	if !ok {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// Bytes returns the raw bytes value, or nil if one doesn't exist. String
+// values are converted.
+func (v Val) Bytes() []byte {
+	switch raw := v.unobfuscated().(type) {
+	case []byte:
+		return raw
+	case string:
+		return []byte(raw)
+	default:
+		return nil
+	}
+}
+
+// StringSlice returns the raw string slice value, or nil if one doesn't exist.
+// Slices that only contain strings are converted.
+func (v Val) StringSlice() []string {
+	switch raw := v.unobfuscated().(type) {
+	case []string:
+		return raw
+
+	case []interface{}:
+		strs := make([]string, 0, len(raw))
+		for _, elem := range raw {
+			s, ok := elem.(string)
+			if !ok {
+				return nil
+			}
+
+			strs = append(strs, s)
+		}
+
+		return strs
+
+	default:
+		return nil
+	}
+}
+
+// Kind reports the type of the raw value. Obfuscated values are reported as
+// strings, given that only obfuscated values are provided.
+func (v Val) Kind() Kind {
+	if v.isObfuscated {
+		return KindString
+	}
+
+	return kindOf(v.raw)
+}
+
+func (v Val) unobfuscated() interface{} {
+	if v.isObfuscated {
+		return nil
+	}
+
+	return v.raw
+}
+
+func toInt64(raw interface{}) (int64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case time.Duration:
+		return int64(n), true
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		u, _ := toUint64(n)
+		if u > math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(u), true
+	case float32:
+		return toInt64(float64(n))
+	case float64:
+		if math.IsNaN(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toUint64(raw interface{}) (uint64, bool) {
+	switch n := raw.(type) {
+	case uint:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case uintptr:
+		return uint64(n), true
+	case float32:
+		return toUint64(float64(n))
+	case float64:
+		if math.IsNaN(n) || n < 0 || n >= math.MaxUint64 {
+			return 0, false
+		}
+
+		return uint64(n), true
+	default:
+		i, ok := toInt64(n)
+		if !ok || i < 0 {
+			return 0, false
+		}
+
+		return uint64(i), true
+	}
+}
+
+func toFloat64(raw interface{}) (float64, bool) {
+	switch n := raw.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		u, _ := toUint64(n)
+		return float64(u), true
+	default:
+		i, ok := toInt64(n)
+		return float64(i), ok
+	}
+}
@@ -2,7 +2,10 @@ package kv
 
 import (
 	"errors"
+	"math"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPair(t *testing.T) {
@@ -92,3 +95,48 @@ func TestValue(t *testing.T) {
 		}
 	})
 }
+
+func TestTypedValues(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		val  Val
+		kind Kind
+		got  func(Val) interface{}
+		want interface{}
+	}{
+		{"int64 from an int", Value(24), KindInt64, func(v Val) interface{} { return v.Int64() }, int64(24)},
+		{"int64 from an uint", Value(uint8(24)), KindUint64, func(v Val) interface{} { return v.Int64() }, int64(24)},
+		{"int64 from a float", Value(24.9), KindFloat64, func(v Val) interface{} { return v.Int64() }, int64(24)},
+		{"int64 from an overflowing uint", Value(uint64(math.MaxUint64)), KindUint64, func(v Val) interface{} { return v.Int64() }, int64(0)},
+		{"uint64 from an uint", Value(uint64(math.MaxUint64)), KindUint64, func(v Val) interface{} { return v.Uint64() }, uint64(math.MaxUint64)},
+		{"uint64 from an int", Value(int32(24)), KindInt64, func(v Val) interface{} { return v.Uint64() }, uint64(24)},
+		{"uint64 from a negative int", Value(-24), KindInt64, func(v Val) interface{} { return v.Uint64() }, uint64(0)},
+		{"float64 from a float", Value(float32(2.5)), KindFloat64, func(v Val) interface{} { return v.Float64() }, 2.5},
+		{"float64 from an int", Value(int64(24)), KindInt64, func(v Val) interface{} { return v.Float64() }, float64(24)},
+		{"duration", Value(time.Second), KindDuration, func(v Val) interface{} { return v.Duration() }, time.Second},
+		{"duration from an int", Value(int64(time.Second)), KindInt64, func(v Val) interface{} { return v.Duration() }, time.Second},
+		{"int64 from a duration", Value(time.Second), KindDuration, func(v Val) interface{} { return v.Int64() }, int64(time.Second)},
+		{"time", Value(now), KindTime, func(v Val) interface{} { return v.Time() }, now},
+		{"bytes", Value([]byte("bytes")), KindBytes, func(v Val) interface{} { return string(v.Bytes()) }, "bytes"},
+		{"bytes from a string", Value("bytes"), KindString, func(v Val) interface{} { return string(v.Bytes()) }, "bytes"},
+		{"string slice", Value([]string{"a", "b"}), KindStringSlice, func(v Val) interface{} { return strings.Join(v.StringSlice(), ",") }, "a,b"},
+		{"string slice from a generic slice", Value([]interface{}{"a", "b"}), KindAny, func(v Val) interface{} { return strings.Join(v.StringSlice(), ",") }, "a,b"},
+		{"string slice from a mixed slice", Value([]interface{}{"a", 1}), KindAny, func(v Val) interface{} { return v.StringSlice() == nil }, true},
+		{"bool", Value(true), KindBool, func(v Val) interface{} { return v.Bool() }, true},
+		{"nil", Value(nil), KindNil, func(v Val) interface{} { return v.Int64() }, int64(0)},
+		{"unsupported types", Value(struct{}{}), KindAny, func(v Val) interface{} { return v.Float64() }, float64(0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.val.Kind(); got != test.kind {
+				t.Errorf("unexpected kind, want %s, got %s", test.kind, got)
+			}
+			if got := test.got(test.val); got != test.want {
+				t.Errorf("unexpected value, want %v, got %v", test.want, got)
+			}
+		})
+	}
+}
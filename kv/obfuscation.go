@@ -0,0 +1,136 @@
+package kv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	redactedValue = "redacted"
+	maskRune      = '*'
+	hashLength    = 16
+)
+
+var (
+	auditLock sync.RWMutex
+	auditor   = func(key, reason string) {}
+)
+
+// Obfuscator produces the value to report in place of the given one.
+type Obfuscator func(val interface{}) string
+
+// Redact fully redacts the value.
+func Redact(interface{}) string {
+	return redactedValue
+}
+
+// MaskAllButLast masks the value, keeping only the last characters visible.
+// Values that are not longer than the visible characters are fully masked,
+// and so are all values when the visible characters are not positive.
+func MaskAllButLast(visible int) Obfuscator {
+	if visible < 0 {
+		visible = 0
+	}
+
+	return func(val interface{}) string {
+		return mask(stringify(val), anyRune, visible)
+	}
+}
+
+// SaltedHash replaces the value with a salted deterministic hash. The same
+// value and salt always produce the same hash, letting obfuscated values be
+// correlated.
+func SaltedHash(salt []byte) Obfuscator {
+	return func(val interface{}) string {
+		mac := hmac.New(sha256.New, salt)
+		_, _ = mac.Write([]byte(stringify(val)))
+
+		return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+	}
+}
+
+// MaskEmail masks the local part of an email address but its first character,
+// keeping the domain and the format. Values that are not email addresses are
+// fully masked.
+func MaskEmail(val interface{}) string {
+	s := stringify(val)
+	at := strings.LastIndexByte(s, '@')
+	if at < 1 {
+		return mask(s, anyRune, 0)
+	}
+
+	local := []rune(s[:at])
+	return string(local[0]) + mask(string(local[1:]), anyRune, 0) + s[at:]
+}
+
+// MaskPhone masks the digits of a phone number but the last four, keeping any
+// other character and thus the format.
+func MaskPhone(val interface{}) string {
+	return mask(stringify(val), unicode.IsDigit, 4)
+}
+
+// mask replaces the runes that satisfy the given function, leaving the last
+// ones as they are. Everything is masked when not enough runes exist.
+func mask(s string, isMasked func(rune) bool, visible int) string {
+	runes := []rune(s)
+
+	var candidates []int
+	for i, r := range runes {
+		if isMasked(r) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	hidden := len(candidates) - visible
+	if hidden <= 0 {
+		hidden = len(candidates)
+	}
+
+	for _, i := range candidates[:hidden] {
+		runes[i] = maskRune
+	}
+
+	return string(runes)
+}
+
+func anyRune(rune) bool {
+	return true
+}
+
+func stringify(val interface{}) string {
+	if s, ok := val.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(val)
+}
+
+// SetRevealAuditor sets the function that gets called every time an
+// obfuscated value is revealed, letting clients keep track of it.
+func SetRevealAuditor(audit func(key, reason string)) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	auditor = audit
+}
+
+// Reveal returns the original value of the given pair, even when it's
+// obfuscated. A reason is required, which is sent to the reveal auditor
+// for obfuscated pairs.
+func Reveal(p Pair, reason string) interface{} {
+	if !p.isObfuscated {
+		return p.raw
+	}
+
+	auditLock.RLock()
+	audit := auditor
+	auditLock.RUnlock()
+
+	audit(p.key, reason)
+	return p.raw
+}
@@ -0,0 +1,116 @@
+package kv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestObfuscators(t *testing.T) {
+	salt := []byte("salt")
+
+	tests := []struct {
+		name       string
+		obfuscator Obfuscator
+		val        interface{}
+		want       string
+	}{
+		{"redacting", Redact, "value", redactedValue},
+		{"masking all but last", MaskAllButLast(4), "4111111111111111", "************1111"},
+		{"masking all but last a short value", MaskAllButLast(4), "1234", "****"},
+		{"masking all but last a non string", MaskAllButLast(2), 123456, "****56"},
+		{"masking all but last with negative visible characters", MaskAllButLast(-2), "1234", "****"},
+		{"masking an email", MaskEmail, "john.doe@example.com", "j*******@example.com"},
+		{"masking an invalid email", MaskEmail, "john.doe", "********"},
+		{"masking a phone", MaskPhone, "+34 612-345-678", "+** ***-**5-678"},
+		{"masking a short phone", MaskPhone, "112", "***"},
+		{"hashing", SaltedHash(salt), "value", SaltedHash(salt)("value")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewObfuscatedWith("key", test.val, test.obfuscator)
+
+			if got := p.String(); got != test.want {
+				t.Errorf("unexpected obfuscated value, want %s, got %s", test.want, got)
+			}
+			if got := p.Value(); got != test.want {
+				t.Errorf("unexpected obfuscated value, want %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSaltedHashes(t *testing.T) {
+	hash := SaltedHash([]byte("salt"))
+
+	if hash("value") != hash("value") {
+		t.Error("the same value had to produce the same hash")
+	}
+	if hash("value") == hash("other") {
+		t.Error("different values had to produce different hashes")
+	}
+	if hash("value") == SaltedHash([]byte("pepper"))("value") {
+		t.Error("different salts had to produce different hashes")
+	}
+	if got := hash("value"); len(got) != hashLength || got == "value" {
+		t.Errorf("unexpected hash, got %s", got)
+	}
+}
+
+func TestObfuscatedTypedValues(t *testing.T) {
+	p := NewObfuscated("key", 24)
+	if got := p.Int(); got != 0 {
+		t.Errorf("unexpected int, want 0, got %d", got)
+	}
+
+	p = NewObfuscated("key", true)
+	if got := p.Bool(); got {
+		t.Errorf("unexpected bool, want false, got %t", got)
+	}
+	if got := p.Kind(); got != KindString {
+		t.Errorf("unexpected kind, want %s, got %s", KindString, got)
+	}
+}
+
+func TestRevealing(t *testing.T) {
+	var audited []string
+	SetRevealAuditor(func(key, reason string) {
+		audited = append(audited, key+": "+reason)
+	})
+	defer SetRevealAuditor(func(string, string) {})
+
+	p := NewObfuscatedWith("email", "john.doe@example.com", MaskEmail)
+	if got := Reveal(p, "sending the welcome email"); got != "john.doe@example.com" {
+		t.Fatalf("unexpected revealed value, got %v", got)
+	}
+	if got := Reveal(New("key", "value"), "not obfuscated"); got != "value" {
+		t.Fatalf("unexpected revealed value, got %v", got)
+	}
+
+	if len(audited) != 1 || audited[0] != "email: sending the welcome email" {
+		t.Fatalf("unexpected audited reveals, got %v", audited)
+	}
+}
+
+func TestPrintingObfuscatedValues(t *testing.T) {
+	pair := NewObfuscated("pw", "hunter2")
+
+	tests := map[string]struct {
+		got  string
+		want string
+	}{
+		"pair":                 {got: fmt.Sprintf("%#v", pair), want: `kv.Pair{key: "pw", obfuscated: "` + redactedValue + `"}`},
+		"value":                {got: fmt.Sprintf("%#v", pair.Val), want: `kv.Val{obfuscated: "` + redactedValue + `"}`},
+		"pairs":                {got: fmt.Sprintf("%#v", Pairs{pair}), want: `kv.Pairs{kv.Pair{key: "pw", obfuscated: "` + redactedValue + `"}}`},
+		"non obfuscated":       {got: fmt.Sprintf("%#v", New("pw", "visible")), want: `kv.Pair{key: "pw", raw: "visible"}`},
+		"non obfuscated value": {got: fmt.Sprintf("%#v", Value(42)), want: `kv.Val{raw: 42}`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.got != test.want {
+				t.Fatalf("unexpected representation, got %s, want %s", test.got, test.want)
+			}
+		})
+	}
+}
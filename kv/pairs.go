@@ -0,0 +1,163 @@
+package kv
+
+import (
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// KeepExisting keeps the existing pair when merging pairs with the same key.
+	KeepExisting ConflictPolicy = iota
+
+	// ReplaceExisting keeps the incoming pair when merging pairs with the same
+	// key. It takes the position of the existing one.
+	ReplaceExisting
+)
+
+// ConflictPolicy decides which pair to keep when merging pairs with the same key.
+type ConflictPolicy int
+
+// Pairs is an ordered collection of pairs. Methods never modify the
+// collection, a new one is provided instead. Pairs keep the order in which
+// their keys were first added.
+type Pairs []Pair
+
+// Get finds the pair for the given key, or an empty pair otherwise. An
+// indicator for the pair existence is returned as well. When the key is
+// duplicated, the last pair is used.
+func (ps Pairs) Get(key string) (Pair, bool) {
+	i := ps.lastIndex(key)
+	if i < 0 {
+		return Pair{}, false
+	}
+
+	return ps[i], true
+}
+
+// Set provides the pairs including the given ones, which replace the
+// existing pairs for the same keys.
+func (ps Pairs) Set(pairs ...Pair) Pairs {
+	return ps.Merge(pairs, ReplaceExisting)
+}
+
+// Merge provides the pairs including the given ones, deciding which pair to
+// keep for duplicated keys with the given policy. The resulting pairs don't
+// contain duplicated keys. Duplicated keys that already exist are resolved
+// by keeping the last pair.
+func (ps Pairs) Merge(pairs Pairs, policy ConflictPolicy) Pairs {
+	merged := make(Pairs, 0, len(ps)+len(pairs))
+	add := func(pair Pair, replace bool) {
+		i := merged.lastIndex(pair.key)
+		switch {
+		case i < 0:
+			merged = append(merged, pair)
+		case replace:
+			merged[i] = pair
+		}
+	}
+
+	for _, pair := range ps {
+		add(pair, true)
+	}
+	for _, pair := range pairs {
+		add(pair, policy == ReplaceExisting)
+	}
+
+	return merged
+}
+
+// Dedupe provides the pairs without duplicated keys, keeping the last pair
+// for each key in the position of the first one.
+func (ps Pairs) Dedupe() Pairs {
+	return ps.Merge(nil, ReplaceExisting)
+}
+
+// Without provides the pairs excluding the ones for the given keys.
+func (ps Pairs) Without(keys ...string) Pairs {
+	without := make(Pairs, 0, len(ps))
+	for _, pair := range ps {
+		if !contains(keys, pair.key) {
+			without = append(without, pair)
+		}
+	}
+
+	return without
+}
+
+// Map provides the pair values indexed by key. When the key is duplicated,
+// the last pair is used. Obfuscated values are provided instead of the
+// original ones.
+func (ps Pairs) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(ps))
+	for _, pair := range ps {
+		m[pair.key] = pair.Value()
+	}
+
+	return m
+}
+
+// Attributes converts the pairs into opentelemetry attributes, keeping the
+// value types when possible. Obfuscated values are provided instead of the
+// original ones.
+func (ps Pairs) Attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(ps))
+	for _, pair := range ps {
+		attrs = append(attrs, pair.attribute())
+	}
+
+	return attrs
+}
+
+// SlogAttrs converts the pairs into slog attributes. Obfuscated values are
+// provided instead of the original ones.
+func (ps Pairs) SlogAttrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(ps))
+	for _, pair := range ps {
+		attrs = append(attrs, slog.Any(pair.key, pair.Value()))
+	}
+
+	return attrs
+}
+
+func (ps Pairs) lastIndex(key string) int {
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i].key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (p Pair) attribute() attribute.KeyValue {
+	key := attribute.Key(p.key)
+
+	switch p.Kind() {
+	case KindBool:
+		return key.Bool(p.Bool())
+	case KindInt64, KindUint64, KindDuration:
+		return key.Int64(p.Int64())
+	case KindFloat64:
+		return key.Float64(p.Float64())
+	case KindString:
+		return key.String(p.String())
+	case KindBytes:
+		return key.String(string(p.Bytes()))
+	case KindStringSlice:
+		return key.StringSlice(p.StringSlice())
+	default:
+		return key.String(fmt.Sprint(p.Value()))
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
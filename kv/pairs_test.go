@@ -0,0 +1,137 @@
+package kv
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPairsLookup(t *testing.T) {
+	pairs := Pairs{New("first", 1), New("second", 2), New("first", 3)}
+
+	if got, exists := pairs.Get("first"); !exists || got.Int() != 3 {
+		t.Fatalf("the last pair had to be found, got %v", got.Value())
+	}
+	if got, exists := pairs.Get("unknown"); exists || got != (Pair{}) {
+		t.Fatalf("no pair had to be found, got %v", got.Value())
+	}
+}
+
+func TestPairsModification(t *testing.T) {
+	existing := Pairs{New("first", 1), New("second", 2)}
+
+	tests := map[string]struct {
+		got  Pairs
+		want Pairs
+	}{
+		"setting pairs": {
+			got:  existing.Set(New("third", 3), New("first", 10)),
+			want: Pairs{New("first", 10), New("second", 2), New("third", 3)},
+		},
+		"merging keeping existing pairs": {
+			got:  existing.Merge(Pairs{New("second", 20), New("third", 3)}, KeepExisting),
+			want: Pairs{New("first", 1), New("second", 2), New("third", 3)},
+		},
+		"merging replacing existing pairs": {
+			got:  existing.Merge(Pairs{New("second", 20), New("third", 3)}, ReplaceExisting),
+			want: Pairs{New("first", 1), New("second", 20), New("third", 3)},
+		},
+		"deduping pairs": {
+			got:  Pairs{New("first", 1), New("second", 2), New("first", 3), New("first", 4)}.Dedupe(),
+			want: Pairs{New("first", 4), New("second", 2)},
+		},
+		"excluding pairs": {
+			got:  existing.Set(New("third", 3)).Without("first", "unknown"),
+			want: Pairs{New("second", 2), New("third", 3)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, test.got, pairComparer); diff != "" {
+				t.Fatalf("unexpected pairs (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("keeping the original pairs", func(t *testing.T) {
+		pairs := make(Pairs, 0, 10)
+		pairs = append(pairs, New("first", 1))
+
+		set := pairs.Set(New("first", 2))
+		_ = pairs.Set(New("second", 2))
+
+		if got := pairs[0].Int(); got != 1 {
+			t.Fatalf("the original pairs can't be modified, got %d", got)
+		}
+		if got := set[0].Int(); got != 2 {
+			t.Fatalf("the derived pairs can't be modified, got %d", got)
+		}
+	})
+}
+
+func TestPairsConversion(t *testing.T) {
+	pairs := Pairs{
+		New("string", "value"),
+		New("int", 42),
+		New("duration", time.Second),
+		New("strings", []string{"one"}),
+		NewObfuscated("secret", "value"),
+		New("int", 43),
+	}
+
+	t.Run("converting to a map", func(t *testing.T) {
+		want := map[string]interface{}{
+			"string":   "value",
+			"int":      43,
+			"duration": time.Second,
+			"strings":  []string{"one"},
+			"secret":   redactedValue,
+		}
+
+		if diff := cmp.Diff(want, pairs.Map()); diff != "" {
+			t.Fatalf("unexpected map (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("converting to opentelemetry attributes", func(t *testing.T) {
+		want := []attribute.KeyValue{
+			attribute.String("string", "value"),
+			attribute.Int64("int", 42),
+			attribute.Int64("duration", int64(time.Second)),
+			attribute.StringSlice("strings", []string{"one"}),
+			attribute.String("secret", redactedValue),
+			attribute.Int64("int", 43),
+		}
+
+		if diff := cmp.Diff(want, pairs.Attributes(), cmp.Comparer(func(a, b attribute.KeyValue) bool {
+			return a.Key == b.Key && a.Value.Emit() == b.Value.Emit()
+		})); diff != "" {
+			t.Fatalf("unexpected attributes (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("converting to slog attributes", func(t *testing.T) {
+		want := []slog.Attr{
+			slog.String("string", "value"),
+			slog.Int("int", 42),
+			slog.Duration("duration", time.Second),
+			slog.Any("strings", []string{"one"}),
+			slog.String("secret", redactedValue),
+			slog.Int("int", 43),
+		}
+
+		got := pairs.SlogAttrs()
+		if len(got) != len(want) {
+			t.Fatalf("unexpected number of attributes, got %d, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if got[i].String() != want[i].String() || got[i].Value.Kind() != want[i].Value.Kind() {
+				t.Errorf("unexpected attribute, got %s, want %s", got[i], want[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,267 @@
+// Package async is a io.Writer implementation that writes log lines into an
+// underlying writer asynchronously, so slow writers don't block clients.
+package async
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+const defaultCapacity = 1024
+
+const (
+	// Block makes writes wait until there's room in the buffer.
+	Block OverflowPolicy = iota
+
+	// DropNewest discards the lines being written when the buffer is full.
+	DropNewest
+
+	// DropOldest discards the oldest buffered lines to make room for the
+	// lines being written when the buffer is full.
+	DropOldest
+)
+
+// OverflowPolicy defines what to do with log lines when the buffer is full.
+type OverflowPolicy int
+
+// Option allows to tweak the writer behavior.
+type Option func(*Writer)
+
+// WithCapacity indicates how many log lines can be buffered at most.
+// Defaults to 1024.
+func WithCapacity(capacity int) Option {
+	return func(w *Writer) {
+		if capacity < 1 {
+			capacity = 1
+		}
+
+		w.buffer = make([][]byte, capacity)
+	}
+}
+
+// WithOverflowPolicy indicates what to do when the buffer is full.
+// Defaults to Block.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(w *Writer) {
+		w.policy = policy
+	}
+}
+
+// Writer implements io.Writer. Log lines –as delimited by new lines– are
+// kept in a bounded ring buffer and written into the underlying writer in
+// the background, one write per line. Once closed, lines are written
+// synchronously instead.
+type Writer struct {
+	out     io.Writer
+	outLock sync.Mutex
+	policy  OverflowPolicy
+
+	lock      sync.Mutex
+	cond      *sync.Cond
+	buffer    [][]byte
+	head      int
+	size      int
+	partial   []byte
+	isWriting bool
+	isClosed  bool
+	isDrained bool
+	dropped   uint64
+	err       error
+	done      chan struct{}
+}
+
+// New returns a new Writer that writes into the given one.
+func New(out io.Writer, opts ...Option) *Writer {
+	w := &Writer{
+		out:    out,
+		policy: Block,
+		buffer: make([][]byte, defaultCapacity),
+		done:   make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.lock)
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	go w.run()
+	return w
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.isClosed {
+		return w.writeClosed(p)
+	}
+
+	w.partial = append(w.partial, p...)
+	i := bytes.LastIndexByte(w.partial, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	// Complete lines are taken out first, as enqueueing can wait for room
+	// and a concurrent flush or close must not enqueue them again.
+	lines := w.partial[:i+1]
+	w.partial = w.partial[i+1:]
+	for len(lines) > 0 {
+		end := bytes.IndexByte(lines, '\n') + 1
+		w.enqueue(lines[:end])
+		lines = lines[end:]
+	}
+
+	return len(p), nil
+}
+
+// Dropped returns how many log lines were discarded because of the
+// overflow policy.
+func (w *Writer) Dropped() uint64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.dropped
+}
+
+// Flush blocks until all the buffered log lines are written, including a
+// trailing line that lacks a new line. The first error produced by the
+// underlying writer since the previous flush, if any, is returned.
+func (w *Writer) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.enqueuePartial()
+	for (w.size > 0 || w.isWriting) && !w.isClosed {
+		w.cond.Wait()
+	}
+
+	err := w.err
+	w.err = nil
+
+	return err
+}
+
+// Close flushes the buffered log lines and stops the background writing.
+// The underlying writer is not closed. The first error produced by the
+// underlying writer since the previous flush, if any, is returned.
+func (w *Writer) Close() error {
+	w.lock.Lock()
+	if w.isClosed {
+		w.lock.Unlock()
+		return nil
+	}
+
+	w.enqueuePartial()
+	w.isClosed = true
+	w.cond.Broadcast()
+	w.lock.Unlock()
+
+	<-w.done
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	err := w.err
+	w.err = nil
+
+	return err
+}
+
+// enqueue adds a copy of the given line into the buffer, applying the
+// overflow policy if necessary. Once closed, the line is written
+// synchronously instead, as the buffer is not drained anymore. The lock must
+// be held.
+func (w *Writer) enqueue(line []byte) {
+	for w.size == len(w.buffer) && !w.isClosed {
+		switch w.policy {
+		case DropNewest:
+			w.dropped++
+			return
+
+		case DropOldest:
+			w.buffer[w.head] = nil
+			w.head = (w.head + 1) % len(w.buffer)
+			w.size--
+			w.dropped++
+
+		default:
+			w.cond.Wait()
+		}
+	}
+
+	if w.isClosed {
+		_, _ = w.writeClosed(line)
+		return
+	}
+
+	w.buffer[(w.head+w.size)%len(w.buffer)] = append([]byte(nil), line...)
+	w.size++
+	w.cond.Broadcast()
+}
+
+// enqueuePartial adds the pending line that lacks a new line, if any, into
+// the buffer. The lock must be held.
+func (w *Writer) enqueuePartial() {
+	if len(w.partial) == 0 || w.isClosed {
+		return
+	}
+
+	w.enqueue(w.partial)
+	w.partial = nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for {
+		for w.size == 0 && !w.isClosed {
+			w.cond.Wait()
+		}
+		if w.size == 0 {
+			w.isDrained = true
+			w.cond.Broadcast()
+			return
+		}
+
+		line := w.buffer[w.head]
+		w.buffer[w.head] = nil
+		w.head = (w.head + 1) % len(w.buffer)
+		w.size--
+		w.isWriting = true
+		w.cond.Broadcast()
+
+		w.lock.Unlock()
+		_, err := w.write(line)
+		w.lock.Lock()
+
+		w.isWriting = false
+		if err != nil && w.err == nil {
+			w.err = err
+		}
+		w.cond.Broadcast()
+	}
+}
+
+// writeClosed writes the given line synchronously once the buffered lines
+// are written, keeping the lines order. The lock must be held.
+func (w *Writer) writeClosed(line []byte) (int, error) {
+	for !w.isDrained {
+		w.cond.Wait()
+	}
+
+	return w.write(line)
+}
+
+// write sends the given line to the underlying writer, making sure that
+// background and synchronous writes don't happen at the same time.
+func (w *Writer) write(line []byte) (int, error) {
+	w.outLock.Lock()
+	defer w.outLock.Unlock()
+
+	return w.out.Write(line)
+}
@@ -0,0 +1,205 @@
+package async
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type gateWriter struct {
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+
+	lock  sync.Mutex
+	lines []string
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.lines = append(w.lines, string(p))
+	return len(p), nil
+}
+
+func (w *gateWriter) written() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return strings.Join(w.lines, "")
+}
+
+func TestWritingLines(t *testing.T) {
+	out := newGateWriter()
+	close(out.release)
+
+	w := New(out)
+	_, _ = w.Write([]byte("first\nsec"))
+	_, _ = w.Write([]byte("ond\nthird"))
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected flush error, got %s", err)
+	}
+
+	if want, got := "first\nsecond\nthird", out.written(); got != want {
+		t.Fatalf("unexpected written lines, want %q, got %q", want, got)
+	}
+	if got := len(out.lines); got != 3 {
+		t.Fatalf("a write per line was expected, got %d writes", got)
+	}
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		written string
+	}{
+		{"dropping the newest lines", DropNewest, "1\n2\n3\n"},
+		{"dropping the oldest lines", DropOldest, "1\n3\n4\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := newGateWriter()
+			w := New(out, WithCapacity(2), WithOverflowPolicy(test.policy))
+
+			_, _ = w.Write([]byte("1\n"))
+			<-out.started
+			_, _ = w.Write([]byte("2\n3\n4\n"))
+
+			close(out.release)
+			if err := w.Close(); err != nil {
+				t.Fatalf("unexpected close error, got %s", err)
+			}
+
+			if got := out.written(); got != test.written {
+				t.Fatalf("unexpected written lines, want %q, got %q", test.written, got)
+			}
+			if got := w.Dropped(); got != 1 {
+				t.Fatalf("unexpected number of dropped lines, want 1, got %d", got)
+			}
+		})
+	}
+}
+
+func TestBlockingWhenTheBufferIsFull(t *testing.T) {
+	out := newGateWriter()
+	w := New(out, WithCapacity(1))
+
+	_, _ = w.Write([]byte("1\n"))
+	<-out.started
+	_, _ = w.Write([]byte("2\n"))
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("3\n"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("the write had to block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(out.release)
+	<-written
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected flush error, got %s", err)
+	}
+
+	if want, got := "1\n2\n3\n", out.written(); got != want {
+		t.Fatalf("unexpected written lines, want %q, got %q", want, got)
+	}
+	if got := w.Dropped(); got != 0 {
+		t.Fatalf("no lines had to be dropped, got %d", got)
+	}
+}
+
+func TestWritingOnceClosed(t *testing.T) {
+	out := newGateWriter()
+	close(out.release)
+
+	w := New(out)
+	_ = w.Close()
+	_, _ = w.Write([]byte("line\n"))
+
+	if want, got := "line\n", out.written(); got != want {
+		t.Fatalf("unexpected written lines, want %q, got %q", want, got)
+	}
+}
+
+func TestClosingWhileBlocked(t *testing.T) {
+	out := newGateWriter()
+	w := New(out, WithCapacity(1))
+
+	_, _ = w.Write([]byte("1\n"))
+	<-out.started
+	_, _ = w.Write([]byte("2\n"))
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("3\n4\n5\n"))
+		close(written)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		_ = w.Close()
+		close(closed)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	close(out.release)
+	<-written
+	<-closed
+
+	if want, got := "1\n2\n3\n4\n5\n", out.written(); got != want {
+		t.Fatalf("unexpected written lines, want %q, got %q", want, got)
+	}
+}
+
+func TestKeepingTheOrderWhenClosing(t *testing.T) {
+	out := newGateWriter()
+	w := New(out, WithCapacity(2))
+
+	_, _ = w.Write([]byte("1\n"))
+	<-out.started
+	_, _ = w.Write([]byte("2\n3\n"))
+
+	closed := make(chan struct{})
+	go func() {
+		_ = w.Close()
+		close(closed)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("4\n"))
+		close(written)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	close(out.release)
+	<-closed
+	<-written
+
+	if want, got := "1\n2\n3\n4\n", out.written(); got != want {
+		t.Fatalf("unexpected written lines, want %q, got %q", want, got)
+	}
+}
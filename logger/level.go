@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"github.com/apex/log"
+)
+
+const (
+	// Debug indicates a log line meant for troubleshooting purposes.
+	Debug Level = iota
+
+	// Info indicates a log line about the regular execution flow.
+	Info
+
+	// Warn indicates a log line about an unexpected but handled situation.
+	Warn
+
+	// Error indicates a log line about a failure.
+	Error
+
+	// Fatal indicates a log line about an unrecoverable failure. Notice that
+	// the program won't be terminated, that's up to the client.
+	Fatal
+)
+
+var _ Leveler = Info
+var _ Leveler = &LevelVar{}
+
+// Level defines the severity of a log line. When given as an argument to the
+// Log method, it determines the log line level.
+type Level int
+
+// Level lets any Level be used as a Leveler.
+func (l Level) Level() Level {
+	return l
+}
+
+// String returns the level name.
+func (l Level) String() string {
+	return l.apex().String()
+}
+
+func (l Level) apex() log.Level {
+	switch l {
+	case Debug:
+		return log.DebugLevel
+	case Warn:
+		return log.WarnLevel
+	case Error:
+		return log.ErrorLevel
+	case Fatal:
+		return log.FatalLevel
+	default:
+		return log.InfoLevel
+	}
+}
+
+// Leveler provides the minimum level a logger will output.
+type Leveler interface {
+	// Level returns the level in use.
+	Level() Level
+}
+
+// LevelVar is a Leveler that can be changed at runtime, even while logging.
+// Its zero value corresponds to the Debug level.
+type LevelVar struct {
+	level int64
+}
+
+// NewLevelVar returns a LevelVar initialized with the given level.
+func NewLevelVar(l Level) *LevelVar {
+	v := &LevelVar{}
+	v.Set(l)
+
+	return v
+}
+
+// Level returns the level in use.
+func (v *LevelVar) Level() Level {
+	return Level(atomic.LoadInt64(&v.level))
+}
+
+// Set changes the level in use.
+func (v *LevelVar) Set(l Level) {
+	atomic.StoreInt64(&v.level, int64(l))
+}
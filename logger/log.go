@@ -3,13 +3,20 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
 	"github.com/apex/log/handlers/json"
 	"github.com/thisiserico/golib/kv"
 	"github.com/thisiserico/golib/oops"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -18,6 +25,18 @@ const (
 
 	// JSONOutput writes json encoded text into the log writer.
 	JSONOutput
+
+	// LogfmtOutput writes logfmt encoded text into the log writer.
+	LogfmtOutput
+
+	// ECSOutput writes json encoded text into the log writer, following the
+	// Elastic Common Schema layout.
+	ECSOutput
+
+	// GCPOutput writes json encoded text into the log writer, following the
+	// Google Cloud Logging structured layout. See WithGCPProjectID to link
+	// log lines to their traces.
+	GCPOutput
 )
 
 // Output defines a way to format log lines before sending them to the writer.
@@ -26,84 +45,243 @@ type Output int
 // Log lets clients output log lines into the previously specified writer.
 // Different argument types –listed below– will be used to define the log line
 // composition. The order is important, as arguments can override. By default,
-// info log lines are provided.
+// info log lines are provided. Lines below the logger minimum level are
+// discarded.
 //
-// - `context.Context`
-//   Known execution indicators are extracted from the context and provided in
-//   the log line as tags.
-// - `string`
-//   The argument will be used as the log message.
-// - `error`
-//   The error message will be used as the log message. An error log line will
-//   be provided. Error details will be extracted and used as tags.
-// - `kv.Pair`
-//   Each pair will be used as a log line tag.
+//   - `context.Context`
+//     Known execution indicators are extracted from the context and provided in
+//     the log line as tags. When the context holds an active span, its trace
+//     and span IDs are provided as well.
+//   - `string`
+//     The argument will be used as the log message.
+//   - `error`
+//     The error message will be used as the log message. An error log line will
+//     be provided. Error details will be extracted and used as tags.
+//   - `kv.Pair`
+//     Each pair will be used as a log line tag.
+//   - `logger.Level`
+//     The log line will be provided using the given level, even when an error
+//     is present.
 //
 // Other types will be ignored.
 type Log func(...interface{})
 
+// With derives a new logging method that includes the given pairs in every
+// log line. The pairs are provided before any other argument, meaning that
+// arguments given when logging can override them. Derived logging methods
+// can be derived again.
+func (l Log) With(pairs ...kv.Pair) Log {
+	bound := make([]interface{}, 0, len(pairs))
+	for _, pair := range pairs {
+		bound = append(bound, pair)
+	}
+
+	return func(args ...interface{}) {
+		l(append(bound[:len(bound):len(bound)], args...)...)
+	}
+}
+
+// Option allows to tweak the logger behavior.
+type Option func(*logger)
+
+// WithLevel indicates the minimum level a log line needs to have in order
+// to be provided. A LevelVar can be used to change it at runtime.
+// Defaults to Info.
+func WithLevel(l Leveler) Option {
+	return func(lg *logger) {
+		lg.level = l
+	}
+}
+
+// WithSampling limits the amount of repeated log lines, as identified by
+// their message and level. During every interval, the first log lines are
+// provided. After that, only every thereafter log line is, dropping all of
+// them when thereafter is 0. Once the interval is over, a log line that
+// reports how many log lines were dropped is provided.
+func WithSampling(first, thereafter int, interval time.Duration) Option {
+	return func(lg *logger) {
+		lg.sampler = newSampler(first, thereafter, interval, lg.reportDropped)
+	}
+}
+
+// WithCaller includes the file and line where the log line was produced.
+func WithCaller() Option {
+	return func(lg *logger) {
+		lg.hasCaller = true
+	}
+}
+
+// WithErrorStacks includes, for log lines that contain an error, the stack
+// captured when the error was created. See oops.CaptureStacks.
+func WithErrorStacks() Option {
+	return func(lg *logger) {
+		lg.hasErrorStacks = true
+	}
+}
+
+// WithGCPProjectID indicates the Google Cloud project the log lines belong
+// to. GCPOutput needs it to link log lines to their traces. Other outputs
+// ignore it.
+func WithGCPProjectID(projectID string) Option {
+	return func(lg *logger) {
+		if g, ok := lg.handler.(*gcpOutput); ok {
+			g.projectID = projectID
+		}
+	}
+}
+
 // New provides a new logging method. When used, the output will be sent to the
 // indicated writer, previously formatting the log line using the specified
 // output method.
-func New(w io.Writer, o Output) Log {
-	var handler log.Handler
-	if o == PlainOutput {
-		handler = cli.New(w)
-	} else {
-		handler = json.New(w)
+func New(w io.Writer, o Output, opts ...Option) Log {
+	var h handler
+	switch o {
+	case PlainOutput:
+		h = &plainOutput{handler: cli.New(w), w: w}
+	case LogfmtOutput:
+		h = &logfmtOutput{w: w}
+	case ECSOutput:
+		h = &ecsOutput{w: w}
+	case GCPOutput:
+		h = &gcpOutput{w: w}
+	default:
+		h = apexOutput{handler: json.New(w)}
 	}
 
+	return newLogger(h, opts...)
+}
+
+func newLogger(h handler, opts ...Option) Log {
 	l := &logger{
-		apex: &log.Logger{
-			Level:   log.InfoLevel,
-			Handler: handler,
-		},
+		handler: h,
+		level:   Info,
+	}
+
+	for _, opt := range opts {
+		opt(l)
 	}
 
 	return l.log
 }
 
 type logger struct {
-	apex *log.Logger
+	handler handler
+	level   Leveler
+	sampler *sampler
+
+	hasCaller      bool
+	hasErrorStacks bool
 }
 
 func (l *logger) log(args ...interface{}) {
-	var isError bool
-	entry := log.NewEntry(l.apex)
-
-	var msg string
+	var (
+		level    = Info
+		hasLevel bool
+	)
+	var (
+		pairs = make(kv.Pairs, 0, len(args)+8)
+		msg   string
+		err   error
+	)
 	for _, arg := range args {
 		switch t := arg.(type) {
 		case context.Context:
 			for _, attr := range kv.AllAttributes(t) {
-				val := attr.Value()
-				if val == nil {
-					continue
+				if attr.Value() != nil {
+					pairs = append(pairs, attr)
 				}
+			}
 
-				entry = entry.WithField(attr.Name(), val)
+			if span := trace.SpanContextFromContext(t); span.IsValid() {
+				pairs = append(pairs,
+					kv.New(traceIDField, span.TraceID().String()),
+					kv.New(spanIDField, span.SpanID().String()),
+					kv.New(traceSampledField, span.IsSampled()),
+				)
 			}
 
 		case string:
 			msg = t
 
 		case error:
-			isError = true
+			if !hasLevel {
+				level = Error
+			}
 			msg = t.Error()
+			err = t
 
-			for _, pair := range oops.Details(t) {
-				entry = entry.WithField(pair.Name(), pair.Value())
-			}
+			pairs = append(pairs, oops.Details(t)...)
 
 		case kv.Pair:
-			entry = entry.WithField(t.Name(), t.Value())
+			pairs = append(pairs, t)
+
+		case Level:
+			level = t
+			hasLevel = true
 		}
 	}
 
-	log := entry.Info
-	if isError {
-		log = entry.Error
+	if level < l.level.Level() {
+		return
+	}
+	if l.sampler != nil && !l.sampler.allow(level, msg) {
+		return
+	}
+
+	if l.hasCaller {
+		pairs = append(pairs, kv.New(callerField, caller()))
 	}
 
-	log(msg)
+	var stack []runtime.Frame
+	if l.hasErrorStacks && err != nil {
+		stack = oops.Stack(err)
+	}
+
+	l.emit(record{
+		level:   level,
+		message: msg,
+		fields:  pairs.Map(),
+		err:     err,
+		stack:   stack,
+	})
+}
+
+func (l *logger) reportDropped(key samplingKey, count int) {
+	l.emit(record{
+		level:   key.level,
+		message: droppedLinesMessage,
+		fields: log.Fields{
+			"sampling.message": key.message,
+			"sampling.dropped": count,
+		},
+	})
+}
+
+func (l *logger) emit(r record) {
+	r.time = time.Now()
+	_ = l.handler.handle(r)
+}
+
+var loggerPackage = reflect.TypeOf(logger{}).PkgPath() + "."
+
+// caller finds the first frame outside of the logger package, considering
+// that slog can be used through the slog handler.
+func caller() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		isLogger := strings.HasPrefix(frame.Function, loggerPackage) && !strings.HasSuffix(frame.File, "_test.go")
+		isSlog := strings.HasPrefix(frame.Function, "log/slog.")
+		if !isLogger && !isSlog {
+			return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File), frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
 }
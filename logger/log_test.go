@@ -1,12 +1,20 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/thisiserico/golib/kv"
 	"github.com/thisiserico/golib/logger/memory"
 	"github.com/thisiserico/golib/oops"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestLoggingExecutionAttributes(t *testing.T) {
@@ -48,6 +56,56 @@ func TestLoggingExecutionAttributes(t *testing.T) {
 	}
 }
 
+func TestLoggingTraceAttributes(t *testing.T) {
+	t.Run("with an active span", func(t *testing.T) {
+		ctx, span := spanContext()
+
+		writer := memory.New()
+		log := New(writer, JSONOutput)
+		log(ctx)
+
+		line, exists := writer.Line(0)
+		if !exists {
+			t.Fatal("no log line was produced")
+		}
+
+		if want, got := span.TraceID().String(), line.Fields["trace_id"]; got != want {
+			t.Fatalf("unexpected trace ID, got %s, want %s", got, want)
+		}
+		if want, got := span.SpanID().String(), line.Fields["span_id"]; got != want {
+			t.Fatalf("unexpected span ID, got %s, want %s", got, want)
+		}
+		if got := line.Fields["trace_sampled"]; got != true {
+			t.Fatalf("unexpected sampled indicator, got %v", got)
+		}
+	})
+
+	t.Run("without an active span", func(t *testing.T) {
+		writer := memory.New()
+		log := New(writer, JSONOutput)
+		log(context.Background())
+
+		line, exists := writer.Line(0)
+		if !exists {
+			t.Fatal("no log line was produced")
+		}
+
+		if got, exists := line.Fields["trace_id"]; exists {
+			t.Fatalf("no trace ID was expected, got %s", got)
+		}
+	})
+}
+
+func spanContext() (context.Context, trace.SpanContext) {
+	span := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:     trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	return trace.ContextWithSpanContext(context.Background(), span), span
+}
+
 func TestLoggingAMessage(t *testing.T) {
 	const message = "message"
 
@@ -121,3 +179,198 @@ func TestLoggingTagPairs(t *testing.T) {
 		t.Fatalf("unexpected log line tag, got %s, want %s", got, value)
 	}
 }
+
+func TestLoggingLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []interface{}
+		level string
+	}{
+		{"by default", []interface{}{"message"}, "info"},
+		{"using an error", []interface{}{oops.Invalid("message")}, "error"},
+		{"indicating a level", []interface{}{Warn, "message"}, "warn"},
+		{"indicating a level along an error", []interface{}{Fatal, oops.Invalid("message")}, "fatal"},
+		{"indicating multiple levels", []interface{}{Warn, Debug, "message"}, "debug"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer := memory.New()
+			log := New(writer, JSONOutput, WithLevel(Debug))
+			log(test.args...)
+
+			line, exists := writer.Line(0)
+			if !exists {
+				t.Fatal("no log line was produced")
+			}
+
+			if got := line.Level; got != test.level {
+				t.Fatalf("unexpected log line level, got %s, want %s", got, test.level)
+			}
+		})
+	}
+}
+
+func TestLoggingBelowTheMinimumLevel(t *testing.T) {
+	t.Run("using the default level", func(t *testing.T) {
+		writer := memory.New()
+		log := New(writer, JSONOutput)
+		log(Debug, "message")
+
+		if _, exists := writer.Line(0); exists {
+			t.Fatal("no log line had to be produced")
+		}
+	})
+
+	t.Run("changing the level at runtime", func(t *testing.T) {
+		level := NewLevelVar(Error)
+
+		writer := memory.New()
+		log := New(writer, JSONOutput, WithLevel(level))
+		log(Warn, "discarded")
+
+		level.Set(Warn)
+		log(Warn, "provided")
+
+		line, exists := writer.Line(0)
+		if !exists {
+			t.Fatal("no log line was produced")
+		}
+		if got := line.Message; got != "provided" {
+			t.Fatalf("unexpected log line message, got %s, want provided", got)
+		}
+		if _, exists := writer.Line(1); exists {
+			t.Fatal("a single log line had to be produced")
+		}
+	})
+}
+
+func TestLoggingWithBoundPairs(t *testing.T) {
+	writer := memory.New()
+	log := New(writer, JSONOutput).
+		With(kv.New("component", "parent"), kv.New("tenant", "tenant")).
+		With(kv.New("component", "child"))
+
+	log("message", kv.New("tenant", "overridden"), kv.New("key", "value"))
+
+	line, exists := writer.Line(0)
+	if !exists {
+		t.Fatal("no log line was produced")
+	}
+
+	want := map[string]interface{}{
+		"component": "child",
+		"tenant":    "overridden",
+		"key":       "value",
+	}
+	for key, value := range want {
+		if got := line.Fields[key]; got != value {
+			t.Fatalf("unexpected log line tag %s, got %s, want %s", key, got, value)
+		}
+	}
+}
+
+func TestLoggingTheCaller(t *testing.T) {
+	writer := memory.New()
+	log := New(writer, JSONOutput, WithCaller())
+
+	_, _, line, _ := runtime.Caller(0)
+	log("message")
+	log.With(kv.New("key", "value"))("message")
+	slog.New(NewSlogHandler(log)).Info("message")
+
+	want := fmt.Sprintf("logger/log_test.go:%d", line+1)
+	for i := 0; i < 3; i++ {
+		logLine, exists := writer.Line(i)
+		if !exists {
+			t.Fatalf("log line %d was not produced", i)
+		}
+
+		if got := logLine.Fields["caller"]; got != want {
+			t.Errorf("unexpected caller, got %s, want %s", got, want)
+		}
+		want = fmt.Sprintf("logger/log_test.go:%d", line+i+2)
+	}
+}
+
+func TestLoggingErrorStacks(t *testing.T) {
+	const function = "github.com/thisiserico/golib/logger.TestLoggingErrorStacks"
+
+	t.Run("as a structured field", func(t *testing.T) {
+		writer := memory.New()
+		log := New(writer, JSONOutput, WithErrorStacks())
+		log(oops.WithStack(oops.Invalid("message")))
+
+		line, exists := writer.Line(0)
+		if !exists {
+			t.Fatal("no log line was produced")
+		}
+
+		frames, ok := line.Fields["stack"].([]interface{})
+		if !ok || len(frames) == 0 {
+			t.Fatalf("unexpected stack, got %v", line.Fields["stack"])
+		}
+		frame, _ := frames[0].(map[string]interface{})
+		if got := frame["function"]; !strings.HasPrefix(got.(string), function) {
+			t.Fatalf("unexpected stack frame function, got %s, want %s", got, function)
+		}
+	})
+
+	t.Run("as indented frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := New(&buf, PlainOutput, WithErrorStacks())
+		log(oops.WithStack(oops.Invalid("message")))
+
+		lines := strings.Split(buf.String(), "\n")
+		if len(lines) < 3 {
+			t.Fatalf("stack frames were expected, got %q", buf.String())
+		}
+		if got := lines[1]; !strings.HasPrefix(strings.TrimSpace(got), function) || !strings.HasPrefix(got, " ") {
+			t.Fatalf("unexpected stack frame, got %q", got)
+		}
+	})
+
+	t.Run("for errors without a stack", func(t *testing.T) {
+		writer := memory.New()
+		log := New(writer, JSONOutput, WithErrorStacks())
+		log(errors.New("message"))
+
+		line, exists := writer.Line(0)
+		if !exists {
+			t.Fatal("no log line was produced")
+		}
+		if got, exists := line.Fields["stack"]; exists {
+			t.Fatalf("no stack was expected, got %v", got)
+		}
+	})
+}
+
+func TestLoggingObfuscatedPairs(t *testing.T) {
+	const email = "john.doe@example.com"
+
+	writer := memory.New()
+	log := New(writer, JSONOutput)
+	log(oops.With(oops.Invalid("message"), kv.NewObfuscatedWith("email", email, kv.MaskEmail)))
+
+	line, exists := writer.Line(0)
+	if !exists {
+		t.Fatal("no log line was produced")
+	}
+
+	if want, got := kv.MaskEmail(email), line.Fields["email"]; got != want {
+		t.Fatalf("unexpected log line tag, got %s, want %s", got, want)
+	}
+}
+
+func BenchmarkLoggingExecutionAttributes(b *testing.B) {
+	ctx := context.Background()
+	ctx = kv.SetStaticAttributes(ctx, "build_id", "service_host", "service_name")
+	ctx = kv.SetDynamicAttributes(ctx, "correlation_id", true)
+
+	log := New(io.Discard, JSONOutput)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log(ctx, "message")
+	}
+}
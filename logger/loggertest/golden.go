@@ -0,0 +1,146 @@
+// Package loggertest simplifies the verification of log lines when running
+// tests, comparing them against golden files.
+package loggertest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thisiserico/golib/logger"
+)
+
+// UpdateFlag is the command-line flag that, when given, makes AssertGolden
+// rewrite the golden files using the produced log lines. The flag is not
+// registered by this package, avoiding clashes with test packages that
+// already define it. Test packages that don't can define it as follows:
+//
+//	var _ = flag.Bool("update", false, "rewrite the golden files")
+const UpdateFlag = "update"
+
+// UpdateEnv is the environment variable that, when set to a true value,
+// works like UpdateFlag.
+const UpdateEnv = "LOGGERTEST_UPDATE"
+
+// volatileKeys matches the tags whose values change on every execution.
+const volatileKeys = `duration|elapsed|latency|[a-z_.]+_duration`
+
+var normalizations = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{
+		regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`),
+		"<timestamp>",
+	},
+	{
+		regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		"<uuid>",
+	},
+	{
+		regexp.MustCompile(`\b[0-9a-f]{32}\b`),
+		"<trace_id>",
+	},
+	{
+		regexp.MustCompile(`("(?:span_id|span\.id|logging\.googleapis\.com/spanId)":\s*)"[0-9a-f]{16}"`),
+		`$1"<span_id>"`,
+	},
+	{
+		regexp.MustCompile(`\b(span_id=)[0-9a-f]{16}\b`),
+		`$1<span_id>`,
+	},
+	{
+		regexp.MustCompile(`("(?:` + volatileKeys + `)":\s*)(-?[0-9.e+]+|"[^"]*")`),
+		`$1"<duration>"`,
+	},
+	{
+		regexp.MustCompile(`\b((?:` + volatileKeys + `)=)("[^"]*"|\S+)`),
+		`$1<duration>`,
+	},
+}
+
+// Recorder implements io.Writer, capturing everything a logging method
+// produces. It's safe for concurrent use.
+type Recorder struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+// New returns a logging method that uses the given output, along with the
+// recorder that captures the log lines it produces.
+func New(o logger.Output, opts ...logger.Option) (logger.Log, *Recorder) {
+	r := &Recorder{}
+	return logger.New(r, o, opts...), r
+}
+
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.buf.Write(p)
+}
+
+// Output returns the captured log lines, normalizing volatile values like
+// timestamps, durations, UUIDs and trace IDs.
+func (r *Recorder) Output() []byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return Normalize(r.buf.Bytes())
+}
+
+// AssertGolden compares the captured log lines against the indicated golden
+// file, failing the test when they differ. When the UpdateFlag flag is given
+// or the UpdateEnv environment variable is enabled, the golden file is
+// rewritten instead.
+func (r *Recorder) AssertGolden(t testing.TB, path string) {
+	t.Helper()
+
+	got := r.Output()
+	if isUpdating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unable to create the golden file directory: %s", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("unable to update the golden file: %s", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read the golden file, use -%s to create it: %s", UpdateFlag, err)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("unexpected log lines for %s (-want +got):\n%s", path, diff)
+	}
+}
+
+func isUpdating() bool {
+	if f := flag.Lookup(UpdateFlag); f != nil {
+		if update, _ := strconv.ParseBool(f.Value.String()); update {
+			return true
+		}
+	}
+
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return update
+}
+
+// Normalize replaces volatile values from the given log lines with stable
+// placeholders.
+func Normalize(lines []byte) []byte {
+	for _, n := range normalizations {
+		lines = n.pattern.ReplaceAll(lines, []byte(n.replacement))
+	}
+
+	return lines
+}
@@ -0,0 +1,83 @@
+package loggertest
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/logger"
+	"github.com/thisiserico/golib/oops"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = flag.Bool(UpdateFlag, false, "rewrite the golden files")
+
+func TestGoldenFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		output logger.Output
+		golden string
+	}{
+		{"using json", logger.JSONOutput, "testdata/json.golden"},
+		{"using logfmt", logger.LogfmtOutput, "testdata/logfmt.golden"},
+		{"using ecs", logger.ECSOutput, "testdata/ecs.golden"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var spanID trace.SpanID
+			random := uuid.New()
+			copy(spanID[:], random[:])
+
+			ctx := kv.SetDynamicAttributes(context.Background(), uuid.New().String(), false)
+			ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: trace.TraceID(uuid.New()),
+				SpanID:  spanID,
+			}))
+
+			log, recorder := New(test.output)
+			log(ctx, "started", kv.New("duration", time.Since(time.Now().Add(-time.Second))))
+			log(ctx, logger.Warn, oops.With(oops.Invalid("failed"), kv.New("event_id", uuid.New().String())))
+
+			recorder.AssertGolden(t, test.golden)
+		})
+	}
+}
+
+func TestUpdatingGoldenFiles(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"using the flag": func(t *testing.T) {
+			if err := flag.Set(UpdateFlag, "true"); err != nil {
+				t.Fatalf("unexpected error, got %s", err)
+			}
+			t.Cleanup(func() { _ = flag.Set(UpdateFlag, "false") })
+		},
+		"using the environment variable": func(t *testing.T) {
+			t.Setenv(UpdateEnv, "true")
+		},
+	}
+
+	for name, enable := range tests {
+		t.Run(name, func(t *testing.T) {
+			enable(t)
+			golden := filepath.Join(t.TempDir(), "logfmt.golden")
+
+			log, recorder := New(logger.LogfmtOutput)
+			log(context.Background(), "started")
+			recorder.AssertGolden(t, golden)
+
+			got, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("the golden file had to be written, got %s", err)
+			}
+			if want := recorder.Output(); string(got) != string(want) {
+				t.Fatalf("unexpected golden file, got %q, want %q", got, want)
+			}
+		})
+	}
+}
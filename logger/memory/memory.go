@@ -2,14 +2,25 @@
 // being produced.
 package memory
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+)
 
 var emptyLine = Line{}
 
 // Writer implements io.Writer and provides a way to fetch the log lines that
-// were produced.
+// were produced. It's safe for concurrent use. The zero value is ready to
+// use.
 type Writer struct {
+	lock  sync.Mutex
 	lines []Line
+
+	// written is closed when a log line is produced. It's only created
+	// when someone waits for log lines.
+	written chan struct{}
 }
 
 // Line encapsulates the different elements that were logged.
@@ -24,6 +35,32 @@ type Line struct {
 	Message string `json:"message"`
 }
 
+// Matcher indicates whether a log line satisfies a condition.
+type Matcher func(Line) bool
+
+// HasLevel matches log lines with the given level.
+func HasLevel(level string) Matcher {
+	return func(l Line) bool {
+		return l.Level == level
+	}
+}
+
+// HasMessage matches log lines with the given message.
+func HasMessage(msg string) Matcher {
+	return func(l Line) bool {
+		return l.Message == msg
+	}
+}
+
+// HasField matches log lines containing the given tag. Notice that values
+// are json decoded, meaning that numbers have to be provided as float64.
+func HasField(key string, val interface{}) Matcher {
+	return func(l Line) bool {
+		got, exists := l.Fields[key]
+		return exists && reflect.DeepEqual(got, val)
+	}
+}
+
 // New returns a new Writer.
 func New() *Writer {
 	return &Writer{
@@ -37,16 +74,102 @@ func (w *Writer) Write(p []byte) (int, error) {
 		return 0, err
 	}
 
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
 	w.lines = append(w.lines, l)
+
+	if w.written != nil {
+		close(w.written)
+		w.written = nil
+	}
+
 	return len(p), nil
 }
 
 // Line fetches the indicated log line. It also returns a boolean indicating
 // whether the requested log line was produced.
 func (w *Writer) Line(index int) (Line, bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
 	if index < len(w.lines) {
 		return w.lines[index], true
 	}
 
 	return emptyLine, false
 }
+
+// Lines fetches all the log lines that were produced.
+func (w *Writer) Lines() []Line {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return append([]Line(nil), w.lines...)
+}
+
+// Find fetches the log lines that satisfy all the given matchers.
+func (w *Writer) Find(matchers ...Matcher) []Line {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return find(w.lines, matchers)
+}
+
+// WaitFor blocks until a log line that satisfies all the given matchers is
+// produced, or the timeout is reached. Already produced log lines are
+// considered as well. It also returns a boolean indicating whether the
+// log line was produced.
+func (w *Writer) WaitFor(timeout time.Duration, matchers ...Matcher) (Line, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		w.lock.Lock()
+		lines := find(w.lines, matchers)
+		if w.written == nil {
+			w.written = make(chan struct{})
+		}
+		written := w.written
+		w.lock.Unlock()
+
+		if len(lines) > 0 {
+			return lines[0], true
+		}
+
+		select {
+		case <-written:
+		case <-deadline.C:
+			return emptyLine, false
+		}
+	}
+}
+
+// Reset discards all the log lines that were produced.
+func (w *Writer) Reset() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.lines = make([]Line, 0)
+}
+
+func find(lines []Line, matchers []Matcher) []Line {
+	var found []Line
+	for _, line := range lines {
+		if matches(line, matchers) {
+			found = append(found, line)
+		}
+	}
+
+	return found
+}
+
+func matches(line Line, matchers []Matcher) bool {
+	for _, matcher := range matchers {
+		if !matcher(line) {
+			return false
+		}
+	}
+
+	return true
+}
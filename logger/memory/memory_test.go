@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func write(w *Writer, level, msg string, fields string) {
+	line := fmt.Sprintf(`{"fields":{%s},"level":%q,"message":%q}`, fields, level, msg)
+	_, _ = w.Write([]byte(line))
+}
+
+func TestWritingConcurrently(t *testing.T) {
+	const goroutines = 50
+	w := New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			write(w, "info", "message", "")
+		}()
+	}
+	wg.Wait()
+
+	if got := len(w.Lines()); got != goroutines {
+		t.Fatalf("unexpected number of log lines, want %d, got %d", goroutines, got)
+	}
+}
+
+func TestFindingLines(t *testing.T) {
+	w := New()
+	write(w, "info", "first", `"key":"value"`)
+	write(w, "error", "second", `"key":"value","attempt":1`)
+	write(w, "error", "third", `"key":"other"`)
+
+	tests := []struct {
+		name     string
+		matchers []Matcher
+		messages []string
+	}{
+		{"without matchers", nil, []string{"first", "second", "third"}},
+		{"by level", []Matcher{HasLevel("error")}, []string{"second", "third"}},
+		{"by message", []Matcher{HasMessage("first")}, []string{"first"}},
+		{"by field", []Matcher{HasField("key", "value")}, []string{"first", "second"}},
+		{"by numeric field", []Matcher{HasField("attempt", float64(1))}, []string{"second"}},
+		{"by several conditions", []Matcher{HasLevel("error"), HasField("key", "value")}, []string{"second"}},
+		{"without matching lines", []Matcher{HasMessage("unknown")}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := w.Find(test.matchers...)
+			if len(lines) != len(test.messages) {
+				t.Fatalf("unexpected number of log lines, want %d, got %d", len(test.messages), len(lines))
+			}
+
+			for i, line := range lines {
+				if got := line.Message; got != test.messages[i] {
+					t.Errorf("unexpected log line message, want %s, got %s", test.messages[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitingForALine(t *testing.T) {
+	t.Run("that gets produced", func(t *testing.T) {
+		w := New()
+		go func() {
+			<-time.After(10 * time.Millisecond)
+			write(w, "info", "ignored", "")
+			write(w, "info", "expected", "")
+		}()
+
+		line, exists := w.WaitFor(time.Second, HasMessage("expected"))
+		if !exists {
+			t.Fatal("the log line had to be produced")
+		}
+		if got := line.Message; got != "expected" {
+			t.Fatalf("unexpected log line message, want expected, got %s", got)
+		}
+	})
+
+	t.Run("that never gets produced", func(t *testing.T) {
+		w := New()
+		write(w, "info", "ignored", "")
+
+		if _, exists := w.WaitFor(10*time.Millisecond, HasMessage("expected")); exists {
+			t.Fatal("no log line had to be found")
+		}
+	})
+}
+
+func TestResetting(t *testing.T) {
+	w := New()
+	write(w, "info", "message", "")
+	w.Reset()
+
+	if _, exists := w.Line(0); exists {
+		t.Fatal("no log lines had to exist")
+	}
+}
+
+func TestUsingTheZeroValue(t *testing.T) {
+	var w Writer
+	write(&w, "info", "message", "")
+
+	if _, exists := w.WaitFor(10*time.Millisecond, HasMessage("message")); !exists {
+		t.Fatal("the log line had to be produced")
+	}
+}
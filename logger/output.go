@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/apex/log"
+	"github.com/thisiserico/golib/kv"
+)
+
+const (
+	traceIDField      = "trace_id"
+	spanIDField       = "span_id"
+	traceSampledField = "trace_sampled"
+	callerField       = "caller"
+	stackField        = "stack"
+
+	ecsVersion = "1.6.0"
+)
+
+var correlationIDField = kv.CorrelationID(context.Background()).Name()
+
+// record holds all the elements that compose a log line.
+type record struct {
+	level   Level
+	time    time.Time
+	message string
+	fields  log.Fields
+	err     error
+	stack   []runtime.Frame
+}
+
+// stackFrame is the structured representation of a stack frame.
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func structuredStack(stack []runtime.Frame) []stackFrame {
+	frames := make([]stackFrame, 0, len(stack))
+	for _, frame := range stack {
+		frames = append(frames, stackFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+	}
+
+	return frames
+}
+
+func textStack(stack []runtime.Frame) string {
+	var b strings.Builder
+	for _, frame := range stack {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+
+	return b.String()
+}
+
+// fieldsWithStack returns a copy of the log line fields that includes the
+// structured stack, if any.
+func (r record) fieldsWithStack() log.Fields {
+	if r.stack == nil {
+		return r.fields
+	}
+
+	fields := make(log.Fields, len(r.fields)+1)
+	for name, val := range r.fields {
+		fields[name] = val
+	}
+	fields[stackField] = structuredStack(r.stack)
+
+	return fields
+}
+
+// handler formats the given log line and sends it to its destination.
+type handler interface {
+	handle(record) error
+}
+
+var (
+	_ handler = apexOutput{}
+	_ handler = &plainOutput{}
+	_ handler = &logfmtOutput{}
+	_ handler = &ecsOutput{}
+	_ handler = &gcpOutput{}
+)
+
+type apexOutput struct {
+	handler log.Handler
+}
+
+func (a apexOutput) handle(r record) error {
+	return a.handler.HandleLog(&log.Entry{
+		Fields:    r.fieldsWithStack(),
+		Level:     r.level.apex(),
+		Timestamp: r.time,
+		Message:   r.message,
+	})
+}
+
+// plainOutput writes the stack frames, if any, indented below the log line.
+type plainOutput struct {
+	lock    sync.Mutex
+	handler log.Handler
+	w       io.Writer
+}
+
+func (p *plainOutput) handle(r record) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	err := p.handler.HandleLog(&log.Entry{
+		Fields:    r.fields,
+		Level:     r.level.apex(),
+		Timestamp: r.time,
+		Message:   r.message,
+	})
+	if err != nil || r.stack == nil {
+		return err
+	}
+
+	for _, frame := range r.stack {
+		if _, err := fmt.Fprintf(p.w, "      %s\n          %s:%d\n", frame.Function, frame.File, frame.Line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type logfmtOutput struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (l *logfmtOutput) handle(r record) error {
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(r.time.UTC().Format(time.RFC3339Nano))
+	b.WriteString(" level=")
+	b.WriteString(r.level.String())
+	b.WriteString(" msg=")
+	b.WriteString(logfmtValue(r.message))
+
+	for _, name := range r.fields.Names() {
+		b.WriteByte(' ')
+		b.WriteString(logfmtKey(name))
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(fmt.Sprint(r.fields.Get(name))))
+	}
+	if r.stack != nil {
+		b.WriteString(" " + stackField + "=")
+		b.WriteString(logfmtValue(textStack(r.stack)))
+	}
+	b.WriteByte('\n')
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	_, err := io.WriteString(l.w, b.String())
+	return err
+}
+
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+
+		return r
+	}, key)
+}
+
+func logfmtValue(val string) string {
+	if val == "" {
+		return `""`
+	}
+
+	for _, r := range val {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return strconv.Quote(val)
+		}
+	}
+
+	return val
+}
+
+type ecsOutput struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (e *ecsOutput) handle(r record) error {
+	line := make(map[string]interface{}, len(r.fields)+6)
+	for name, val := range r.fields {
+		line[name] = val
+	}
+
+	line["@timestamp"] = r.time.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+	line["log.level"] = r.level.String()
+	line["message"] = r.message
+	line["ecs.version"] = ecsVersion
+	if r.err != nil {
+		line["error.message"] = r.err.Error()
+	}
+	if r.stack != nil {
+		line["error.stack_trace"] = textStack(r.stack)
+	}
+	moveField(line, correlationIDField, "labels.correlation_id")
+	moveField(line, traceIDField, "trace.id")
+	moveField(line, spanIDField, "span.id")
+
+	return encodeJSON(&e.lock, e.w, line)
+}
+
+type gcpOutput struct {
+	lock      sync.Mutex
+	w         io.Writer
+	projectID string
+}
+
+func (g *gcpOutput) handle(r record) error {
+	line := make(map[string]interface{}, len(r.fields)+5)
+	for name, val := range r.fields {
+		line[name] = val
+	}
+
+	line["time"] = r.time.UTC().Format(time.RFC3339Nano)
+	line["severity"] = gcpSeverity(r.level)
+	line["message"] = r.message
+	if r.err != nil {
+		line["error.message"] = r.err.Error()
+	}
+	if r.stack != nil {
+		line["stack_trace"] = textStack(r.stack)
+	}
+	// Traces can only be linked when the project is known. Otherwise, the
+	// trace ID is kept as it is.
+	if traceID, exists := r.fields[traceIDField]; exists && g.projectID != "" {
+		line["logging.googleapis.com/trace"] = fmt.Sprintf("projects/%s/traces/%s", g.projectID, traceID)
+		delete(line, traceIDField)
+	}
+	moveField(line, spanIDField, "logging.googleapis.com/spanId")
+	moveField(line, traceSampledField, "logging.googleapis.com/trace_sampled")
+
+	return encodeJSON(&g.lock, g.w, line)
+}
+
+// moveField renames the given log line field, if it exists.
+func moveField(line map[string]interface{}, from, to string) {
+	val, exists := line[from]
+	if !exists {
+		return
+	}
+
+	delete(line, from)
+	line[to] = val
+}
+
+func gcpSeverity(l Level) string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Warn:
+		return "WARNING"
+	case Error:
+		return "ERROR"
+	case Fatal:
+		return "CRITICAL"
+	default:
+		return "INFO"
+	}
+}
+
+func encodeJSON(lock *sync.Mutex, w io.Writer, line map[string]interface{}) error {
+	js, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	_, err = w.Write(append(js, '\n'))
+	return err
+}
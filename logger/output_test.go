@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/oops"
+)
+
+func TestLogfmtOutput(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, LogfmtOutput)
+	log(Warn, "a message", kv.New("key", "value"), kv.New("quoted", `with "quotes"`), kv.New("empty", ""))
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "time=") || !strings.HasSuffix(got, "\n") {
+		t.Fatalf("unexpected log line, got %q", got)
+	}
+
+	want := ` level=warn msg="a message" empty="" key=value quoted="with \"quotes\""` + "\n"
+	if !strings.HasSuffix(got, want) {
+		t.Fatalf("unexpected log line, got %q, want suffix %q", got, want)
+	}
+}
+
+func TestStructuredJSONOutputs(t *testing.T) {
+	const correlationID = "correlation_id"
+
+	tests := []struct {
+		output  Output
+		want    map[string]interface{}
+		keys    []string
+		missing []string
+	}{
+		{
+			output: ECSOutput,
+			want: map[string]interface{}{
+				"log.level":             "error",
+				"message":               "message",
+				"error.message":         "message",
+				"labels.correlation_id": correlationID,
+				"ecs.version":           ecsVersion,
+				"key":                   "value",
+			},
+			keys:    []string{"@timestamp"},
+			missing: []string{"trace.id", correlationIDField},
+		},
+		{
+			output: GCPOutput,
+			want: map[string]interface{}{
+				"severity":      "ERROR",
+				"message":       "message",
+				"error.message": "message",
+				"key":           "value",
+			},
+			keys:    []string{"time"},
+			missing: []string{"logging.googleapis.com/trace"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			ctx := kv.SetDynamicAttributes(context.Background(), correlationID, false)
+			err := oops.With(oops.Invalid("message"), kv.New("key", "value"))
+
+			var buf bytes.Buffer
+			log := New(&buf, test.output)
+			log(ctx, err)
+
+			var line map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("unexpected log line %q: %s", buf.String(), err)
+			}
+
+			for key, value := range test.want {
+				if got := line[key]; got != value {
+					t.Errorf("unexpected log line field %s, got %v, want %v", key, got, value)
+				}
+			}
+			for _, key := range test.keys {
+				if _, exists := line[key]; !exists {
+					t.Errorf("the log line field %s had to exist", key)
+				}
+			}
+			for _, key := range test.missing {
+				if got, exists := line[key]; exists {
+					t.Errorf("the log line field %s can't exist, got %v", key, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGCPSeverities(t *testing.T) {
+	tests := []struct {
+		level    Level
+		severity string
+	}{
+		{Debug, "DEBUG"},
+		{Info, "INFO"},
+		{Warn, "WARNING"},
+		{Error, "ERROR"},
+		{Fatal, "CRITICAL"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.severity, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := New(&buf, GCPOutput, WithLevel(Debug))
+			log(test.level, "message")
+
+			var line map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("unexpected log line %q: %s", buf.String(), err)
+			}
+
+			if got := line["severity"]; got != test.severity {
+				t.Fatalf("unexpected severity, got %s, want %s", got, test.severity)
+			}
+		})
+	}
+}
+
+func TestStructuredJSONOutputsWithAnActiveSpan(t *testing.T) {
+	ctx, span := spanContext()
+	ctx = kv.SetDynamicAttributes(ctx, "correlation_id", false)
+
+	tests := []struct {
+		output  Output
+		opts    []Option
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			output: ECSOutput,
+			want: map[string]interface{}{
+				"trace.id":              span.TraceID().String(),
+				"span.id":               span.SpanID().String(),
+				"labels.correlation_id": "correlation_id",
+			},
+			missing: []string{traceIDField, spanIDField, correlationIDField},
+		},
+		{
+			output: GCPOutput,
+			opts:   []Option{WithGCPProjectID("project")},
+			want: map[string]interface{}{
+				"logging.googleapis.com/trace":         "projects/project/traces/" + span.TraceID().String(),
+				"logging.googleapis.com/spanId":        span.SpanID().String(),
+				"logging.googleapis.com/trace_sampled": true,
+			},
+			missing: []string{traceIDField, spanIDField, traceSampledField},
+		},
+		{
+			output: GCPOutput,
+			want: map[string]interface{}{
+				traceIDField:                           span.TraceID().String(),
+				"logging.googleapis.com/spanId":        span.SpanID().String(),
+				"logging.googleapis.com/trace_sampled": true,
+			},
+			missing: []string{"logging.googleapis.com/trace"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			var buf bytes.Buffer
+			log := New(&buf, test.output, test.opts...)
+			log(ctx, "message")
+
+			var line map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("unexpected log line %q: %s", buf.String(), err)
+			}
+
+			for key, value := range test.want {
+				if got := line[key]; got != value {
+					t.Errorf("unexpected log line field %s, got %v, want %v", key, got, value)
+				}
+			}
+			for _, key := range test.missing {
+				if got, exists := line[key]; exists {
+					t.Errorf("the log line field %s can't exist, got %v", key, got)
+				}
+			}
+		})
+	}
+}
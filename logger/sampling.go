@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+const droppedLinesMessage = "log lines dropped by sampling"
+
+type samplingKey struct {
+	level   Level
+	message string
+}
+
+type samplingCounter struct {
+	seen    int
+	dropped int
+}
+
+// sampler decides which log lines get provided. Counters are kept per
+// message and level, and reset on every interval. Once an interval is over,
+// the number of dropped lines is reported for every key that dropped any.
+type sampler struct {
+	first      int
+	thereafter int
+	interval   time.Duration
+	report     func(samplingKey, int)
+
+	lock              sync.Mutex
+	windowEnd         time.Time
+	counters          map[samplingKey]*samplingCounter
+	isReportScheduled bool
+}
+
+func newSampler(first, thereafter int, interval time.Duration, report func(samplingKey, int)) *sampler {
+	return &sampler{
+		first:      first,
+		thereafter: thereafter,
+		interval:   interval,
+		report:     report,
+		counters:   make(map[samplingKey]*samplingCounter),
+	}
+}
+
+func (s *sampler) allow(level Level, msg string) bool {
+	s.lock.Lock()
+
+	now := time.Now()
+	var dropped map[samplingKey]int
+	if !now.Before(s.windowEnd) {
+		dropped = s.rotate(now)
+	}
+
+	key := samplingKey{level: level, message: msg}
+	counter, exists := s.counters[key]
+	if !exists {
+		counter = &samplingCounter{}
+		s.counters[key] = counter
+	}
+	counter.seen++
+
+	allowed := counter.seen <= s.first ||
+		(s.thereafter > 0 && (counter.seen-s.first)%s.thereafter == 0)
+	if !allowed {
+		counter.dropped++
+
+		if !s.isReportScheduled {
+			s.isReportScheduled = true
+			time.AfterFunc(s.windowEnd.Sub(now), s.flush)
+		}
+	}
+
+	s.lock.Unlock()
+
+	s.reportDropped(dropped)
+	return allowed
+}
+
+// flush reports the dropped lines once the current interval is over.
+func (s *sampler) flush() {
+	s.lock.Lock()
+
+	now := time.Now()
+	var dropped map[samplingKey]int
+	if !now.Before(s.windowEnd) {
+		dropped = s.rotate(now)
+	}
+
+	s.lock.Unlock()
+
+	s.reportDropped(dropped)
+}
+
+// rotate starts a new interval, returning the dropped lines during the
+// previous one. The lock must be held.
+func (s *sampler) rotate(now time.Time) map[samplingKey]int {
+	dropped := make(map[samplingKey]int)
+	for key, counter := range s.counters {
+		if counter.dropped > 0 {
+			dropped[key] = counter.dropped
+		}
+	}
+
+	s.counters = make(map[samplingKey]*samplingCounter)
+	s.windowEnd = now.Add(s.interval)
+	s.isReportScheduled = false
+
+	return dropped
+}
+
+func (s *sampler) reportDropped(dropped map[samplingKey]int) {
+	for key, count := range dropped {
+		s.report(key, count)
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thisiserico/golib/logger/memory"
+)
+
+func TestSamplingRepeatedLines(t *testing.T) {
+	writer := memory.New()
+	log := New(writer, JSONOutput, WithSampling(2, 3, 50*time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		log("repeated")
+	}
+	log(Warn, "repeated")
+
+	for i := 0; i < 5; i++ {
+		if _, exists := writer.Line(i); !exists {
+			t.Fatalf("log line %d had to be produced", i)
+		}
+	}
+	if _, exists := writer.Line(5); exists {
+		t.Fatal("the remaining log lines had to be dropped")
+	}
+
+	line, exists := writer.WaitFor(time.Second, memory.HasMessage(droppedLinesMessage))
+	if !exists {
+		t.Fatal("the dropped log lines had to be reported")
+	}
+	if got := line.Fields["sampling.message"]; got != "repeated" {
+		t.Fatalf("unexpected sampled message, got %s, want repeated", got)
+	}
+	if got := line.Fields["sampling.dropped"]; got != float64(6) {
+		t.Fatalf("unexpected number of dropped lines, got %v, want 6", got)
+	}
+}
+
+func TestSamplingFromMultipleGoroutines(t *testing.T) {
+	const goroutines = 10
+
+	writer := memory.New()
+	log := New(writer, JSONOutput, WithSampling(goroutines, 0, time.Minute))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2*goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log("repeated")
+		}()
+	}
+	wg.Wait()
+
+	if _, exists := writer.Line(goroutines - 1); !exists {
+		t.Fatal("the first log lines had to be produced")
+	}
+	if _, exists := writer.Line(goroutines); exists {
+		t.Fatal("the remaining log lines had to be dropped")
+	}
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/thisiserico/golib/kv"
+)
+
+var _ handler = slogOutput{}
+var _ slog.Handler = &slogHandler{}
+
+// NewSlog provides a new logging method. When used, the log lines will be
+// sent through the indicated slog handler.
+func NewSlog(handler slog.Handler, opts ...Option) Log {
+	return newLogger(slogOutput{handler: handler}, opts...)
+}
+
+// NewSlogHandler exposes the given logging method as a slog handler. This
+// lets libraries that use slog produce log lines into the same stream.
+// Records are always considered enabled, it's the logging method minimum
+// level the one that determines whether a line is provided. The record
+// context is used as any other context given to the logging method.
+func NewSlogHandler(log Log) slog.Handler {
+	return &slogHandler{log: log}
+}
+
+type slogOutput struct {
+	handler slog.Handler
+}
+
+func (s slogOutput) handle(r record) error {
+	ctx := context.Background()
+	level := r.level.slog()
+	if !s.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	rec := slog.NewRecord(r.time, level, r.message, 0)
+	fields := r.fieldsWithStack()
+	for _, name := range fields.Names() {
+		rec.AddAttrs(slog.Any(name, fields.Get(name)))
+	}
+
+	return s.handler.Handle(ctx, rec)
+}
+
+func (l Level) slog() slog.Level {
+	switch l {
+	case Debug:
+		return slog.LevelDebug
+	case Warn:
+		return slog.LevelWarn
+	case Error:
+		return slog.LevelError
+	case Fatal:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type slogHandler struct {
+	log    Log
+	prefix string
+}
+
+func (h *slogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	args := []interface{}{ctx, levelFromSlog(record.Level), record.Message}
+	record.Attrs(func(attr slog.Attr) bool {
+		for _, pair := range pairsFromAttr(h.prefix, attr) {
+			args = append(args, pair)
+		}
+
+		return true
+	})
+
+	h.log(args...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var pairs kv.Pairs
+	for _, attr := range attrs {
+		pairs = append(pairs, pairsFromAttr(h.prefix, attr)...)
+	}
+
+	return &slogHandler{
+		log:    h.log.With(pairs...),
+		prefix: h.prefix,
+	}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{
+		log:    h.log,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+func levelFromSlog(l slog.Level) Level {
+	switch {
+	case l < slog.LevelInfo:
+		return Debug
+	case l < slog.LevelWarn:
+		return Info
+	case l < slog.LevelError:
+		return Warn
+	case l < slog.LevelError+4:
+		return Error
+	default:
+		return Fatal
+	}
+}
+
+// pairsFromAttr flattens the given attribute, prefixing group members with
+// the group name. Empty attributes are ignored, as slog handlers should do.
+func pairsFromAttr(prefix string, attr slog.Attr) kv.Pairs {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return nil
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		return kv.Pairs{kv.New(prefix+attr.Key, attr.Value.Any())}
+	}
+
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+
+	var pairs kv.Pairs
+	for _, member := range attr.Value.Group() {
+		pairs = append(pairs, pairsFromAttr(prefix, member)...)
+	}
+
+	return pairs
+}
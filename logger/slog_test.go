@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/logger/memory"
+	"github.com/thisiserico/golib/oops"
+)
+
+func TestLoggingThroughASlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewSlog(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	log(oops.With(oops.Invalid("message"), kv.New("key", "value")))
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("unexpected log line %q: %s", buf.String(), err)
+	}
+
+	if got := line[slog.MessageKey]; got != "message" {
+		t.Fatalf("unexpected log line message, got %s, want message", got)
+	}
+	if got := line[slog.LevelKey]; got != "ERROR" {
+		t.Fatalf("unexpected log line level, got %s, want ERROR", got)
+	}
+	if got := line["key"]; got != "value" {
+		t.Fatalf("unexpected log line tag, got %s, want value", got)
+	}
+}
+
+func TestUsingTheLoggerAsASlogHandler(t *testing.T) {
+	const correlationID = "correlation_id"
+	ctx := kv.SetDynamicAttributes(context.Background(), correlationID, false)
+
+	writer := memory.New()
+	log := slog.New(NewSlogHandler(New(writer, JSONOutput)))
+	log.With("bound", "value").
+		WithGroup("group").
+		WarnContext(ctx, "message", "key", "value", slog.Group("nested", "key", 1))
+
+	line, exists := writer.Line(0)
+	if !exists {
+		t.Fatal("no log line was produced")
+	}
+
+	if got := line.Message; got != "message" {
+		t.Fatalf("unexpected log line message, got %s, want message", got)
+	}
+	if got := line.Level; got != "warn" {
+		t.Fatalf("unexpected log line level, got %s, want warn", got)
+	}
+
+	want := map[string]interface{}{
+		"bound":              "value",
+		"group.key":          "value",
+		"group.nested.key":   float64(1),
+		"ctx.correlation_id": correlationID,
+	}
+	for key, value := range want {
+		if got := line.Fields[key]; got != value {
+			t.Fatalf("unexpected log line tag %s, got %v, want %v", key, got, value)
+		}
+	}
+}
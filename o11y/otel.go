@@ -20,10 +20,13 @@ func Configure(log logger.Log) {
 }
 
 // Attributes extracts all the known pairs from the context and converts
-// them into a slice of attributes.
+// them into a slice of attributes. Attributes for keys created by clients
+// are included as well.
 func Attributes(ctx context.Context) []attribute.KeyValue {
-	return []attribute.KeyValue{
+	attrs := []attribute.KeyValue{
 		attribute.String("correlation_id", kv.CorrelationID(ctx).String()),
 		attribute.Bool("is_dry_run", kv.IsDryRun(ctx).Bool()),
 	}
+
+	return append(attrs, kv.CustomAttributes(ctx).Attributes()...)
 }
@@ -157,26 +157,7 @@ func (s *spanProcessor) printSpan(tree spanTree, id tracelib.SpanID, traceStart,
 
 	appendAttributes := func(attrs []attribute.KeyValue) {
 		for _, attr := range attrs {
-			var val interface{}
-
-			switch attr.Value.Type() {
-			case attribute.BOOL:
-				val = attr.Value.AsBool()
-
-			case attribute.INT64:
-				val = attr.Value.AsInt64()
-
-			case attribute.FLOAT64:
-				val = attr.Value.AsFloat64()
-
-			case attribute.STRING:
-				val = attr.Value.AsString()
-
-			default:
-				continue
-			}
-
-			logAttrs = append(logAttrs, kv.New(string(attr.Key), val))
+			logAttrs = append(logAttrs, kv.New(string(attr.Key), attr.Value.AsInterface()))
 		}
 	}
 
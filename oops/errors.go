@@ -30,6 +30,17 @@ var (
 	ErrTransient = errors.New("transient")
 )
 
+var typeNames = map[error]string{
+	ErrCancelled:   "ErrCancelled",
+	ErrDecode:      "ErrDecode",
+	ErrEncode:      "ErrEncode",
+	ErrExistent:    "ErrExistent",
+	ErrInvalid:     "ErrInvalid",
+	ErrNonExistent: "ErrNonExistent",
+	ErrTimeout:     "ErrTimeout",
+	ErrTransient:   "ErrTransient",
+}
+
 // Cancelled creates a new error that satisfies errors.Is(err, ErrCancelled).
 func Cancelled(msg string, args ...interface{}) error {
 	return newError(ErrCancelled, msg, args...)
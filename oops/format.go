@@ -0,0 +1,68 @@
+package oops
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format prints the error message for the %v and %s verbs, and the quoted
+// error message for the %q verb. The %+v verb prints, on top of the error
+// message, the error type, the details, the messages of the wrapped errors
+// and the stack frames, when available. Obfuscated details are never
+// revealed.
+func (se *structuredError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			se.formatVerbose(s)
+			return
+		}
+
+		_, _ = io.WriteString(s, se.Error())
+
+	case 's':
+		_, _ = io.WriteString(s, se.Error())
+
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", se.Error())
+
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(%s)", verb, se.Error())
+	}
+}
+
+func (se *structuredError) formatVerbose(w io.Writer) {
+	_, _ = io.WriteString(w, se.Error())
+
+	if name := typeName(se); name != "" {
+		_, _ = fmt.Fprintf(w, "\n\ttype: %s", name)
+	}
+
+	if details := Details(se); len(details) > 0 {
+		pairs := make([]string, 0, len(details))
+		for _, pair := range details {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", pair.Name(), pair.Value()))
+		}
+
+		_, _ = fmt.Fprintf(w, "\n\tdetails: %s", strings.Join(pairs, " "))
+	}
+
+	seen := map[string]bool{se.Error(): true}
+	walk(se, 0, func(err error, _ int) {
+		if msg := err.Error(); !seen[msg] {
+			seen[msg] = true
+			_, _ = fmt.Fprintf(w, "\n\tcaused by: %s", msg)
+		}
+	})
+
+	for _, frame := range Stack(se) {
+		_, _ = fmt.Fprintf(w, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
+
+// typeName provides the name of the outermost error type in the tree of
+// errors, or an empty string when there's none.
+func typeName(err error) string {
+	return typeNames[Type(err)]
+}
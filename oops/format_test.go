@@ -0,0 +1,69 @@
+package oops
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/thisiserico/golib/kv"
+)
+
+func TestFormatting(t *testing.T) {
+	err := With(
+		fmt.Errorf("handling: %w", Transient("storing: %w", With(errors.New("connection refused"), kv.New("host", "db")))),
+		kv.New("user", 42),
+		kv.NewObfuscated("password", "secret"),
+	)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "handling: storing: connection refused"},
+		{"%s", "handling: storing: connection refused"},
+		{"%q", `"handling: storing: connection refused"`},
+		{"%d", "%!d(handling: storing: connection refused)"},
+		{
+			"%+v",
+			strings.Join([]string{
+				"handling: storing: connection refused",
+				"\ttype: ErrTransient",
+				"\tdetails: user=42 password=redacted host=db",
+				"\tcaused by: storing: connection refused",
+				"\tcaused by: connection refused",
+			}, "\n"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			if got := fmt.Sprintf(test.format, err); got != test.want {
+				t.Fatalf("unexpected formatting, got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if got := fmt.Sprintf("%+v", err); strings.Contains(got, "secret") {
+		t.Fatalf("obfuscated details can't be revealed, got %q", got)
+	}
+}
+
+func TestFormattingStacks(t *testing.T) {
+	err := WithStack(Invalid("oops"))
+
+	if got := fmt.Sprintf("%v", err); got != "oops" {
+		t.Fatalf("unexpected formatting, got %s", got)
+	}
+
+	lines := strings.Split(fmt.Sprintf("%+v", err), "\n")
+	if len(lines) < 4 || lines[0] != "oops" || lines[1] != "\ttype: ErrInvalid" {
+		t.Fatalf("unexpected formatting, got %q", lines)
+	}
+	if got := lines[2]; got != "\tgithub.com/thisiserico/golib/oops.TestFormattingStacks" {
+		t.Fatalf("unexpected stack frame function, got %q", got)
+	}
+	if got := lines[3]; !strings.HasPrefix(got, "\t\t") || !strings.Contains(got, "format_test.go:") {
+		t.Fatalf("unexpected stack frame location, got %q", got)
+	}
+}
@@ -6,73 +6,222 @@ package oops
 import (
 	"errors"
 	"fmt"
+	"runtime"
+	"sync/atomic"
 
 	"github.com/thisiserico/golib/kv"
 )
 
+const maxStackDepth = 32
+
+var capturesStacks atomic.Bool
+
+// CaptureStacks indicates whether errors capture the stack when created.
+// Capturing stacks has a cost, hence it's disabled by default. WithStack can
+// be used to capture a stack regardless.
+func CaptureStacks(enabled bool) {
+	capturesStacks.Store(enabled)
+}
+
 // With creates a new error, mergin previously key-value pairs with the
-// new ones given.
+// new ones given, which take precedence for duplicated keys. The stack of
+// the given error is kept if it has one, otherwise one is captured when
+// capturing stacks is enabled.
 func With(err error, pairs ...kv.Pair) error {
-	var details []kv.Pair
+	return with(err, pairs, capturesStacks.Load())
+}
+
+// WithStack works like With, except that a stack is always captured when
+// the given error doesn't have one.
+func WithStack(err error, pairs ...kv.Pair) error {
+	return with(err, pairs, true)
+}
 
+func with(err error, pairs []kv.Pair, captureStack bool) error {
+	se := &structuredError{
+		origin:    err,
+		isWrapper: true,
+	}
+
+	var details kv.Pairs
 	if structured, ok := err.(*structuredError); ok {
+		se.typology = structured.typology
+		se.stack = structured.stack
 		details = structured.details
 	}
-	details = append(details, pairs...)
 
-	return &structuredError{
-		origin:  err,
-		details: details,
+	// Set provides a new collection, meaning that the details of the given
+	// error are never shared with the new one.
+	se.details = details.Set(pairs...)
+
+	if se.stack == nil && captureStack {
+		se.stack = callers(2)
 	}
+
+	return se
 }
 
-// Details extracts all the key-value pairs from the given error.
-func Details(err error) []kv.Pair {
-	if err == nil {
+// Join creates a new error that wraps all the given ones, as errors.Join
+// does. The key-value pairs of every joined error are kept, and can be
+// extracted as usual. Nil errors are discarded, nil is returned when all the
+// given errors are nil.
+func Join(errs ...error) error {
+	joined := errors.Join(errs...)
+	if joined == nil {
 		return nil
 	}
 
-	var structured *structuredError
-	if !errors.As(err, &structured) {
-		return Details(errors.Unwrap(err))
-	}
+	return &structuredError{origin: joined, isWrapper: true}
+}
+
+// Details extracts all the key-value pairs from the given error, including
+// the ones in every branch of joined errors. For duplicated keys, the pairs
+// of the outermost errors take precedence, followed by the ones in the
+// first branches.
+func Details(err error) kv.Pairs {
+	var details kv.Pairs
+	walk(err, 0, func(err error, _ int) {
+		if structured, ok := err.(*structuredError); ok && len(structured.details) > 0 {
+			details = details.Merge(structured.details, kv.KeepExisting)
+		}
+	})
 
-	return append(structured.details, Details(errors.Unwrap(structured))...)
+	return details
 }
 
 // Detail will find the key-value pair from the given error if exists, or an
 // empty pair otherwise. An indicator for the pair existence is returned as well.
 func Detail(err error, key string) (kv.Pair, bool) {
-	for _, pair := range Details(err) {
-		if pair.Name() == key {
-			return pair, true
+	return Details(err).Get(key)
+}
+
+// Type returns the outermost error type in the tree of errors, like
+// ErrInvalid, or nil when none of the errors has a type.
+func Type(err error) error {
+	var typology error
+	walk(err, 0, func(err error, _ int) {
+		if structured, ok := err.(*structuredError); ok && structured.typology != nil && typology == nil {
+			typology = structured.typology
+		}
+	})
+
+	return typology
+}
+
+// Stack returns the stack captured when the error was created, being it the
+// deepest one in the tree of errors. No frames are returned when none of
+// the errors in the tree captured one.
+func Stack(err error) []runtime.Frame {
+	var (
+		stack   []uintptr
+		deepest = -1
+	)
+	walk(err, 0, func(err error, depth int) {
+		if structured, ok := err.(*structuredError); ok && structured.stack != nil && depth > deepest {
+			stack = structured.stack
+			deepest = depth
 		}
+	})
+
+	if len(stack) == 0 {
+		return nil
 	}
 
-	return kv.Pair{}, false
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(stack)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+
+		if !more {
+			break
+		}
+	}
+
+	return frames
 }
 
+// structuredError holds an error type along with key-value pairs. Errors
+// created by constructors use the origin as their own message, while errors
+// created with With or Join wrap the origin.
 type structuredError struct {
-	typology error
-	origin   error
-	details  []kv.Pair
+	typology  error
+	origin    error
+	isWrapper bool
+	details   kv.Pairs
+	stack     []uintptr
 }
 
 func newError(err error, msg string, args ...interface{}) error {
-	return &structuredError{
+	se := &structuredError{
 		typology: err,
 		origin:   fmt.Errorf(msg, args...),
 	}
+	if capturesStacks.Load() {
+		se.stack = callers(2)
+	}
+
+	return se
+}
+
+// callers captures the program counters of the current stack, skipping the
+// given number of frames on top of the caller.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+
+	return pcs[:n]
 }
 
 func (se structuredError) Error() string {
 	return se.origin.Error()
 }
 
+// Is only matches the error type, as errors.Is already visits the wrapped
+// errors through Unwrap. The only exception are errors created from a message
+// that wraps several ones, which Unwrap can't provide.
 func (se *structuredError) Is(target error) bool {
-	return errors.Is(se.typology, target) || errors.Is(se.origin, target)
+	if errors.Is(se.typology, target) {
+		return true
+	}
+
+	if _, ok := se.origin.(interface{ Unwrap() []error }); ok && !se.isWrapper {
+		return errors.Is(se.origin, target)
+	}
+
+	return false
 }
 
 func (se structuredError) Unwrap() error {
+	if se.isWrapper {
+		return se.origin
+	}
+
 	return errors.Unwrap(se.origin)
 }
+
+// walk visits the given error and all the errors it wraps, depth first,
+// including every branch of errors that wrap several ones.
+func walk(err error, depth int, visit func(err error, depth int)) {
+	if err == nil {
+		return
+	}
+
+	visit(err, depth)
+	for _, wrapped := range wrappedBy(err) {
+		walk(wrapped, depth+1, visit)
+	}
+}
+
+func wrappedBy(err error) []error {
+	switch wrapper := err.(type) {
+	case *structuredError:
+		return []error{wrapper.origin}
+	case interface{ Unwrap() []error }:
+		return wrapper.Unwrap()
+	case interface{ Unwrap() error }:
+		return []error{wrapper.Unwrap()}
+	default:
+		return nil
+	}
+}
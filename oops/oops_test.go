@@ -3,7 +3,11 @@ package oops
 import (
 	"errors"
 	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
 
 	"github.com/google/uuid"
 	"github.com/thisiserico/golib/kv"
@@ -107,7 +111,6 @@ func TestContextualDetails(t *testing.T) {
 		{
 			With(Cancelled("oops"), kv.New("key", 1), kv.New("key", 2)),
 			[]kv.Pair{
-				kv.New("key", 1),
 				kv.New("key", 2),
 			},
 			"oops",
@@ -115,7 +118,6 @@ func TestContextualDetails(t *testing.T) {
 		{
 			With(With(Cancelled("oops"), kv.New("key", "inner")), kv.New("key", "outer")),
 			[]kv.Pair{
-				kv.New("key", "inner"),
 				kv.New("key", "outer"),
 			},
 			"oops",
@@ -147,6 +149,9 @@ func TestContextualDetails(t *testing.T) {
 	for _, test := range tests {
 		t.Run("", func(t *testing.T) {
 			details := Details(test.input)
+			if len(details) < len(test.details) {
+				t.Fatalf("unexpected number of pairs, want at least %d, got %d", len(test.details), len(details))
+			}
 
 			for i, pair := range test.details {
 				if got := details[i]; got != pair {
@@ -192,6 +197,12 @@ func TestContextualDetail(t *testing.T) {
 			kv.New("inner", "most"),
 			true,
 		},
+		{
+			With(fmt.Errorf("oops: %w", With(errors.New("inner"), kv.New("key", "inner"))), kv.New("key", "outer")),
+			"key",
+			kv.New("key", "outer"),
+			true,
+		},
 	}
 
 	for _, test := range tests {
@@ -207,3 +218,374 @@ func TestContextualDetail(t *testing.T) {
 		})
 	}
 }
+
+func TestStack(t *testing.T) {
+	const caller = "github.com/thisiserico/golib/oops.TestStack"
+
+	CaptureStacks(true)
+	defer CaptureStacks(false)
+
+	tests := []struct {
+		name  string
+		input error
+	}{
+		{"using a constructor", Invalid("oops")},
+		{"adding details", With(errors.New("oops"), kv.New("key", "value"))},
+		{"adding details to a structured error", With(Invalid("oops"), kv.New("key", "value"))},
+		{"wrapping a structured error", fmt.Errorf("oops: %w", Invalid("inner"))},
+		{"forcing a stack", WithStack(errors.New("oops"))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frames := Stack(test.input)
+			if len(frames) == 0 {
+				t.Fatal("a stack had to be captured")
+			}
+
+			if got := frames[0].Function; got != caller {
+				t.Errorf("unexpected stack origin, want %s, got %s", caller, got)
+			}
+		})
+	}
+
+	if frames := Stack(errors.New("oops")); frames != nil {
+		t.Errorf("no stack was expected, got %v", frames)
+	}
+}
+
+func TestOptingInToStacks(t *testing.T) {
+	const caller = "github.com/thisiserico/golib/oops.TestOptingInToStacks"
+
+	t.Run("without capturing stacks", func(t *testing.T) {
+		for _, err := range []error{Invalid("oops"), With(errors.New("oops"), kv.New("key", "value"))} {
+			if frames := Stack(err); frames != nil {
+				t.Fatalf("no stack was expected, got %v", frames)
+			}
+		}
+	})
+
+	t.Run("capturing a stack per call", func(t *testing.T) {
+		err := WithStack(Invalid("oops"), kv.New("key", "value"))
+
+		frames := Stack(err)
+		if len(frames) == 0 || !strings.HasPrefix(frames[0].Function, caller) {
+			t.Fatalf("unexpected stack, got %v", frames)
+		}
+		if _, exists := Detail(err, "key"); !exists {
+			t.Fatal("a key-value pair had to exist in the error")
+		}
+		if !errors.Is(err, ErrInvalid) {
+			t.Fatal("the error type had to be kept")
+		}
+	})
+
+	t.Run("keeping an existing stack", func(t *testing.T) {
+		err := WithStack(errors.New("oops"))
+		if got, want := Stack(WithStack(err)), Stack(err); got[0] != want[0] {
+			t.Fatalf("unexpected stack origin, got %v, want %v", got[0], want[0])
+		}
+	})
+}
+
+func TestMultiErrors(t *testing.T) {
+	first := With(Invalid("first"), kv.New("branch", "first"), kv.New("first", 1))
+	second := With(Timeout("second"), kv.New("branch", "second"), kv.New("second", 2))
+
+	tests := []struct {
+		name  string
+		input error
+	}{
+		{"joining structured errors", Join(first, nil, second)},
+		{"joining with the standard library", errors.Join(first, second)},
+		{"wrapping several errors", fmt.Errorf("several: %w, %w", first, second)},
+		{"wrapping a joined error", With(Cancelled("outer: %w", errors.Join(first, second)), kv.New("outer", true))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{"first", "second"} {
+				if _, exists := Detail(test.input, key); !exists {
+					t.Errorf("the %s branch details had to exist", key)
+				}
+			}
+
+			if pair, _ := Detail(test.input, "branch"); pair.String() != "first" {
+				t.Errorf("the first branch details had to take precedence, got %s", pair.String())
+			}
+
+			if !errors.Is(test.input, ErrInvalid) || !errors.Is(test.input, ErrTimeout) {
+				t.Error("the error had to match every branch type")
+			}
+		})
+	}
+
+	t.Run("keeping the outermost details", func(t *testing.T) {
+		err := With(Join(first, second), kv.New("branch", "outer"))
+
+		if pair, _ := Detail(err, "branch"); pair.String() != "outer" {
+			t.Fatalf("the outermost details had to take precedence, got %s", pair.String())
+		}
+		if got := err.Error(); got != "first\nsecond" {
+			t.Fatalf("unexpected error message, got %q", got)
+		}
+	})
+
+	t.Run("joining no errors", func(t *testing.T) {
+		if err := Join(nil, nil); err != nil {
+			t.Fatalf("no error was expected, got %s", err)
+		}
+	})
+
+	t.Run("finding the deepest stack", func(t *testing.T) {
+		err := Join(errors.New("plain"), fmt.Errorf("wrapped: %w", WithStack(errors.New("inner"))))
+
+		if frames := Stack(err); len(frames) == 0 {
+			t.Fatal("a stack had to be found")
+		}
+	})
+}
+
+const (
+	wrappingWith = iota
+	wrappingFormat
+	wrappingType
+	wrappingJoin
+	wrappingKinds
+)
+
+var wrappingTypes = []struct {
+	constructor func(string, ...interface{}) error
+	typology    error
+}{
+	{Cancelled, ErrCancelled},
+	{Invalid, ErrInvalid},
+	{Timeout, ErrTimeout},
+	{Transient, ErrTransient},
+}
+
+type wrappingStep struct {
+	kind  int
+	typ   int
+	pairs []kv.Pair
+}
+
+// wrapping is a random sequence of wrapping steps, applied from the
+// innermost error to the outermost one.
+type wrapping []wrappingStep
+
+func (wrapping) Generate(r *rand.Rand, size int) reflect.Value {
+	steps := make(wrapping, r.Intn(size+1))
+	for i := range steps {
+		steps[i] = wrappingStep{
+			kind: r.Intn(wrappingKinds),
+			typ:  r.Intn(len(wrappingTypes)),
+		}
+
+		for j := r.Intn(4); j > 0; j-- {
+			// A small set of keys makes duplicated keys likely.
+			key := string(rune('a' + r.Intn(3)))
+			steps[i].pairs = append(steps[i].pairs, kv.New(key, r.Int()))
+		}
+	}
+
+	return reflect.ValueOf(steps)
+}
+
+// wrappingModel keeps track of what the resulting error is expected to be.
+type wrappingModel struct {
+	message string
+	details map[string]interface{}
+	types   []error
+}
+
+func (w wrapping) apply(err error) (error, wrappingModel) {
+	model := wrappingModel{
+		message: err.Error(),
+		details: make(map[string]interface{}),
+	}
+
+	for _, step := range w {
+		switch step.kind {
+		case wrappingWith:
+			err = With(err, step.pairs...)
+			for _, pair := range step.pairs {
+				model.details[pair.Name()] = pair.Value()
+			}
+
+		case wrappingFormat:
+			err = fmt.Errorf("format: %w", err)
+			model.message = "format: " + model.message
+
+		case wrappingType:
+			typ := wrappingTypes[step.typ]
+			err = typ.constructor("type: %w", err)
+			model.message = "type: " + model.message
+			model.types = append(model.types, typ.typology)
+
+		case wrappingJoin:
+			err = Join(err, errors.New("sibling"))
+			model.message += "\nsibling"
+		}
+	}
+
+	return err, model
+}
+
+func TestComposingErrorsProperties(t *testing.T) {
+	t.Run("keeping the message, types and details", func(t *testing.T) {
+		property := func(w wrapping) bool {
+			err, model := w.apply(errors.New("origin"))
+
+			if err.Error() != model.message {
+				return false
+			}
+			for _, typology := range model.types {
+				if !errors.Is(err, typology) {
+					return false
+				}
+			}
+
+			return reflect.DeepEqual(Details(err).Map(), model.details)
+		}
+
+		if err := quick.Check(property, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("wrapping without skipping layers", func(t *testing.T) {
+		property := func(w wrapping, pairs wrapping) bool {
+			inner, _ := w.apply(errors.New("origin"))
+
+			var extra []kv.Pair
+			for _, step := range pairs {
+				extra = append(extra, step.pairs...)
+			}
+
+			outer := With(inner, extra...)
+			if errors.Unwrap(outer) != inner || !errors.Is(outer, inner) {
+				return false
+			}
+
+			var structured *structuredError
+			if errors.As(inner, &structured) {
+				if !errors.As(outer, &structured) {
+					return false
+				}
+			}
+
+			return outer.Error() == inner.Error()
+		}
+
+		if err := quick.Check(property, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("not sharing details between errors", func(t *testing.T) {
+		property := func(w wrapping, first, second []int) bool {
+			base, model := w.apply(errors.New("origin"))
+			base = With(base, kv.New("shared", 0))
+			model.details["shared"] = 0
+
+			var firstErr, secondErr error = base, base
+			for i, val := range first {
+				firstErr = With(firstErr, kv.New(fmt.Sprintf("first.%d", i%3), val), kv.New("shared", val))
+			}
+			for i, val := range second {
+				secondErr = With(secondErr, kv.New(fmt.Sprintf("second.%d", i%3), val), kv.New("shared", -val))
+			}
+
+			if !reflect.DeepEqual(Details(base).Map(), model.details) {
+				return false
+			}
+			for key := range Details(firstErr).Map() {
+				if strings.HasPrefix(key, "second.") {
+					return false
+				}
+			}
+			for key := range Details(secondErr).Map() {
+				if strings.HasPrefix(key, "first.") {
+					return false
+				}
+			}
+
+			return true
+		}
+
+		if err := quick.Check(property, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestComposingErrors(t *testing.T) {
+	inner := Invalid("inner")
+	outer := With(inner, kv.New("key", "value"))
+
+	if got := errors.Unwrap(outer); got != inner {
+		t.Fatalf("the wrapped error had to be unwrapped, got %v", got)
+	}
+	if got := typeName(outer); got != "ErrInvalid" {
+		t.Fatalf("the error type had to be kept, got %s", got)
+	}
+	if got := Details(inner); len(got) != 0 {
+		t.Fatalf("the wrapped error details can't be modified, got %v", got)
+	}
+}
+
+type countingError struct {
+	visits *int
+}
+
+func (e countingError) Error() string {
+	return "counting"
+}
+
+func (e countingError) Is(error) bool {
+	*e.visits++
+	return false
+}
+
+func TestMatchingDeeplyWrappedErrors(t *testing.T) {
+	var visits int
+	err := error(countingError{visits: &visits})
+	for i := 0; i < 64; i++ {
+		if i == 32 {
+			err = Invalid("wrapping: %w", err)
+		}
+		err = With(err, kv.New("depth", i))
+	}
+
+	if errors.Is(err, ErrTimeout) {
+		t.Fatal("the error type can't match")
+	}
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatal("the wrapped error type had to match")
+	}
+	if visits != 1 {
+		t.Fatalf("every wrapped error had to be visited once, got %d visits", visits)
+	}
+}
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		input error
+		want  error
+	}{
+		{errors.New("oops"), nil},
+		{Invalid("oops"), ErrInvalid},
+		{With(Timeout("oops"), kv.New("key", "value")), ErrTimeout},
+		{Cancelled("outer: %w", Invalid("inner")), ErrCancelled},
+		{fmt.Errorf("outer: %w", Join(errors.New("plain"), NonExistent("inner"))), ErrNonExistent},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			if got := Type(test.input); got != test.want {
+				t.Fatalf("unexpected type, got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/thisiserico/golib/oops"
+)
+
+// ProblemContentType is the media type problem details use.
+const ProblemContentType = "application/problem+json"
+
+const blankType = "about:blank"
+
+var standardMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// Problem holds the problem details of an error, as defined in RFC 7807.
+type Problem struct {
+	// Type identifies the problem type. Defaults to about:blank.
+	Type string
+
+	// Title summarizes the problem type, being it the status text. Statuses
+	// without a standard text use their own, like "Client Closed Request".
+	Title string
+
+	// Status holds the HTTP status code.
+	Status int
+
+	// Detail explains the specific occurrence of the problem. It's only
+	// provided for client errors, preventing internal details from leaking.
+	Detail string
+
+	// Instance identifies the specific occurrence of the problem.
+	Instance string
+
+	// Extensions holds additional members, built from the non-obfuscated
+	// error details. Members that clash with the standard ones are ignored.
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON encodes the problem details, including the extension members
+// at the top level.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+len(standardMembers))
+	for name, val := range p.Extensions {
+		members[name] = val
+	}
+	for _, name := range standardMembers {
+		delete(members, name)
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// Problem provides the problem details for the given error. Obfuscated
+// error details are never included.
+func (m *Mapper) Problem(err error) Problem {
+	status := m.HTTPStatus(err)
+	p := Problem{
+		Type:   blankType,
+		Title:  statusText(status),
+		Status: status,
+	}
+
+	if status >= 400 && status < 500 && err != nil {
+		p.Detail = err.Error()
+	}
+
+	for _, pair := range oops.Details(err) {
+		if pair.IsObfuscated() {
+			continue
+		}
+
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions[pair.Name()] = pair.Value()
+	}
+
+	return p
+}
+
+// statusText provides the text for the given HTTP status code, including the
+// non-standard ones. Unknown status codes use the text of their class.
+func statusText(status int) string {
+	if text := http.StatusText(status); text != "" {
+		return text
+	}
+
+	switch {
+	case status == StatusClientClosedRequest:
+		return "Client Closed Request"
+	case status >= 400 && status < 500:
+		return "Client Error"
+	case status >= 500 && status < 600:
+		return "Server Error"
+	default:
+		return "Unknown Status"
+	}
+}
+
+// WriteProblem writes the problem details for the given error as the HTTP
+// response, using the mapped status code.
+func (m *Mapper) WriteProblem(w http.ResponseWriter, err error) error {
+	p := m.Problem(err)
+
+	js, encodeErr := json.Marshal(p)
+	if encodeErr != nil {
+		return oops.Encode("status: encoding problem details: %w", encodeErr)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	_, writeErr := w.Write(js)
+
+	return writeErr
+}
+
+// NewProblem works like Mapper.Problem, using the default mappings.
+func NewProblem(err error) Problem {
+	return defaultMapper.Problem(err)
+}
+
+// WriteProblem works like Mapper.WriteProblem, using the default mappings.
+func WriteProblem(w http.ResponseWriter, err error) error {
+	return defaultMapper.WriteProblem(w, err)
+}
@@ -0,0 +1,117 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/oops"
+)
+
+func TestWritingProblems(t *testing.T) {
+	tests := map[string]struct {
+		input error
+		want  map[string]interface{}
+	}{
+		"client error": {
+			input: oops.With(
+				oops.NonExistent("user %d not found", 42),
+				kv.New("user_id", 42),
+				kv.NewObfuscated("email", "john.doe@example.com"),
+				kv.New("status", "clashing"),
+			),
+			want: map[string]interface{}{
+				"type":    "about:blank",
+				"title":   "Not Found",
+				"status":  404.0,
+				"detail":  "user 42 not found",
+				"user_id": 42.0,
+			},
+		},
+		"non standard status": {
+			input: oops.Cancelled("request cancelled"),
+			want: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Client Closed Request",
+				"status": 499.0,
+				"detail": "request cancelled",
+			},
+		},
+		"server error": {
+			input: oops.With(errors.New("connection refused"), kv.New("attempt", 3)),
+			want: map[string]interface{}{
+				"type":    "about:blank",
+				"title":   "Internal Server Error",
+				"status":  500.0,
+				"attempt": 3.0,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := WriteProblem(rec, test.input); err != nil {
+				t.Fatalf("unexpected error, got %s", err)
+			}
+
+			if got := rec.Code; got != int(test.want["status"].(float64)) {
+				t.Errorf("unexpected status code, got %d", got)
+			}
+			if got := rec.Header().Get("Content-Type"); got != ProblemContentType {
+				t.Errorf("unexpected content type, got %s", got)
+			}
+			if body := rec.Body.String(); strings.Contains(body, "john.doe") {
+				t.Errorf("obfuscated details can't be revealed, got %s", body)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("unexpected error, got %s", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("unexpected problem details (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProblemInstance(t *testing.T) {
+	p := NewProblem(oops.Invalid("oops"))
+	p.Instance = "/users/42"
+
+	js, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error, got %s", err)
+	}
+
+	want := `{"detail":"oops","instance":"/users/42","status":400,"title":"Bad Request","type":"about:blank"}`
+	if got := string(js); got != want {
+		t.Fatalf("unexpected problem details, got %s, want %s", got, want)
+	}
+
+	if got := NewProblem(nil).Status; got != http.StatusOK {
+		t.Fatalf("unexpected status, got %d", got)
+	}
+}
+
+func TestProblemTitles(t *testing.T) {
+	tests := map[int]string{
+		http.StatusNotFound:       "Not Found",
+		StatusClientClosedRequest: "Client Closed Request",
+		460:                       "Client Error",
+		599:                       "Server Error",
+		99:                        "Unknown Status",
+	}
+
+	for status, want := range tests {
+		if got := statusText(status); got != want {
+			t.Errorf("unexpected title for %d, got %q, want %q", status, got, want)
+		}
+	}
+}
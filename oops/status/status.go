@@ -0,0 +1,182 @@
+// Package status maps oops error types to HTTP status codes and gRPC codes,
+// and the other way around. Errors can also be provided as RFC 7807 problem
+// details.
+package status
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/thisiserico/golib/oops"
+	"google.golang.org/grpc/codes"
+)
+
+// StatusClientClosedRequest indicates that the client closed the request
+// before the server could respond. It's not a standard status code, though
+// it's widely used to report cancelled requests.
+const StatusClientClosedRequest = 499
+
+var defaultMapper = New()
+
+// Option allows to tweak the mapper behavior.
+type Option func(*Mapper)
+
+// WithMapping maps the given error type to the given HTTP status code and
+// gRPC code. It takes precedence over the default mappings and the previous
+// custom mappings, letting them be overridden. Error types are matched using
+// errors.Is.
+func WithMapping(typology error, httpStatus int, grpcCode codes.Code) Option {
+	return func(m *Mapper) {
+		m.custom = append([]mapping{{typology, httpStatus, grpcCode}}, m.custom...)
+	}
+}
+
+type mapping struct {
+	typology   error
+	httpStatus int
+	grpcCode   codes.Code
+}
+
+// Mapper maps error types to HTTP status codes and gRPC codes, and the
+// other way around.
+type Mapper struct {
+	custom   []mapping
+	builtIns []mapping
+}
+
+// New provides a new mapper. By default, oops error types are mapped as
+// follows. Other errors, including oops.ErrEncode ones, are considered
+// internal errors, reported as 500 Internal Server Error and Unknown:
+//
+//   - `oops.ErrInvalid`: 400 Bad Request, InvalidArgument
+//   - `oops.ErrDecode`: 400 Bad Request, InvalidArgument
+//   - `oops.ErrNonExistent`: 404 Not Found, NotFound
+//   - `oops.ErrExistent`: 409 Conflict, AlreadyExists
+//   - `oops.ErrCancelled`: 499 Client Closed Request, Canceled
+//   - `oops.ErrTransient`: 503 Service Unavailable, Unavailable
+//   - `oops.ErrTimeout`: 504 Gateway Timeout, DeadlineExceeded
+func New(opts ...Option) *Mapper {
+	m := &Mapper{
+		builtIns: []mapping{
+			{oops.ErrInvalid, http.StatusBadRequest, codes.InvalidArgument},
+			{oops.ErrDecode, http.StatusBadRequest, codes.InvalidArgument},
+			{oops.ErrNonExistent, http.StatusNotFound, codes.NotFound},
+			{oops.ErrExistent, http.StatusConflict, codes.AlreadyExists},
+			{oops.ErrCancelled, StatusClientClosedRequest, codes.Canceled},
+			{oops.ErrTransient, http.StatusServiceUnavailable, codes.Unavailable},
+			{oops.ErrTimeout, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// HTTPStatus provides the HTTP status code for the given error, being it 200
+// when no error is given and 500 for unknown errors.
+func (m *Mapper) HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	if mp, exists := m.find(err); exists {
+		return mp.httpStatus
+	}
+
+	return http.StatusInternalServerError
+}
+
+// GRPCCode provides the gRPC code for the given error, being it OK when no
+// error is given and Unknown for unknown errors.
+func (m *Mapper) GRPCCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	if mp, exists := m.find(err); exists {
+		return mp.grpcCode
+	}
+
+	return codes.Unknown
+}
+
+// FromHTTPStatus provides the error type for the given HTTP status code, or
+// nil when it's not mapped to any error type. When several error types use
+// the same status code, the custom ones take precedence, followed by the
+// first default one.
+func (m *Mapper) FromHTTPStatus(status int) error {
+	for _, mp := range m.mappings() {
+		if mp.httpStatus == status {
+			return mp.typology
+		}
+	}
+
+	return nil
+}
+
+// FromGRPCCode provides the error type for the given gRPC code, or nil when
+// it's not mapped to any error type. When several error types use the same
+// code, the custom ones take precedence, followed by the first default one.
+func (m *Mapper) FromGRPCCode(code codes.Code) error {
+	for _, mp := range m.mappings() {
+		if mp.grpcCode == code {
+			return mp.typology
+		}
+	}
+
+	return nil
+}
+
+// find looks for the mapping of the given error. Custom error types are
+// matched first. Then, the outermost oops error type is used, falling back to
+// any error type in the tree of errors.
+func (m *Mapper) find(err error) (mapping, bool) {
+	for _, mp := range m.custom {
+		if errors.Is(err, mp.typology) {
+			return mp, true
+		}
+	}
+
+	if typology := oops.Type(err); typology != nil {
+		for _, mp := range m.builtIns {
+			if mp.typology == typology {
+				return mp, true
+			}
+		}
+	}
+
+	for _, mp := range m.builtIns {
+		if errors.Is(err, mp.typology) {
+			return mp, true
+		}
+	}
+
+	return mapping{}, false
+}
+
+func (m *Mapper) mappings() []mapping {
+	return append(m.custom[:len(m.custom):len(m.custom)], m.builtIns...)
+}
+
+// HTTPStatus works like Mapper.HTTPStatus, using the default mappings.
+func HTTPStatus(err error) int {
+	return defaultMapper.HTTPStatus(err)
+}
+
+// GRPCCode works like Mapper.GRPCCode, using the default mappings.
+func GRPCCode(err error) codes.Code {
+	return defaultMapper.GRPCCode(err)
+}
+
+// FromHTTPStatus works like Mapper.FromHTTPStatus, using the default mappings.
+func FromHTTPStatus(status int) error {
+	return defaultMapper.FromHTTPStatus(status)
+}
+
+// FromGRPCCode works like Mapper.FromGRPCCode, using the default mappings.
+func FromGRPCCode(code codes.Code) error {
+	return defaultMapper.FromGRPCCode(code)
+}
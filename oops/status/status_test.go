@@ -0,0 +1,104 @@
+package status
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/thisiserico/golib/kv"
+	"github.com/thisiserico/golib/oops"
+	"google.golang.org/grpc/codes"
+)
+
+var errPaymentRequired = errors.New("payment required")
+
+func TestMappingErrors(t *testing.T) {
+	tests := []struct {
+		input      error
+		httpStatus int
+		grpcCode   codes.Code
+	}{
+		{nil, http.StatusOK, codes.OK},
+		{oops.Invalid("oops"), http.StatusBadRequest, codes.InvalidArgument},
+		{oops.Decode("oops"), http.StatusBadRequest, codes.InvalidArgument},
+		{oops.NonExistent("oops"), http.StatusNotFound, codes.NotFound},
+		{oops.Existent("oops"), http.StatusConflict, codes.AlreadyExists},
+		{oops.Cancelled("oops"), StatusClientClosedRequest, codes.Canceled},
+		{oops.Transient("oops"), http.StatusServiceUnavailable, codes.Unavailable},
+		{oops.Timeout("oops"), http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{oops.Encode("oops"), http.StatusInternalServerError, codes.Unknown},
+		{errors.New("oops"), http.StatusInternalServerError, codes.Unknown},
+		{oops.With(fmt.Errorf("wrapped: %w", oops.NonExistent("oops")), kv.New("key", "value")), http.StatusNotFound, codes.NotFound},
+		{oops.Timeout("outer: %w", oops.Invalid("inner")), http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{fmt.Errorf("wrapped: %w", oops.ErrExistent), http.StatusConflict, codes.AlreadyExists},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.input), func(t *testing.T) {
+			if got := HTTPStatus(test.input); got != test.httpStatus {
+				t.Errorf("unexpected http status, got %d, want %d", got, test.httpStatus)
+			}
+			if got := GRPCCode(test.input); got != test.grpcCode {
+				t.Errorf("unexpected grpc code, got %s, want %s", got, test.grpcCode)
+			}
+		})
+	}
+}
+
+func TestMappingStatuses(t *testing.T) {
+	tests := []struct {
+		httpStatus int
+		grpcCode   codes.Code
+		want       error
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument, oops.ErrInvalid},
+		{http.StatusNotFound, codes.NotFound, oops.ErrNonExistent},
+		{http.StatusConflict, codes.AlreadyExists, oops.ErrExistent},
+		{StatusClientClosedRequest, codes.Canceled, oops.ErrCancelled},
+		{http.StatusServiceUnavailable, codes.Unavailable, oops.ErrTransient},
+		{http.StatusGatewayTimeout, codes.DeadlineExceeded, oops.ErrTimeout},
+		{http.StatusInternalServerError, codes.Internal, nil},
+		{http.StatusOK, codes.OK, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.httpStatus), func(t *testing.T) {
+			if got := FromHTTPStatus(test.httpStatus); got != test.want {
+				t.Errorf("unexpected error type, got %v, want %v", got, test.want)
+			}
+			if got := FromGRPCCode(test.grpcCode); got != test.want {
+				t.Errorf("unexpected error type, got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCustomMappings(t *testing.T) {
+	mapper := New(
+		WithMapping(errPaymentRequired, http.StatusPaymentRequired, codes.FailedPrecondition),
+		WithMapping(oops.ErrTransient, http.StatusTooManyRequests, codes.ResourceExhausted),
+	)
+
+	err := fmt.Errorf("charging: %w", errPaymentRequired)
+	if got := mapper.HTTPStatus(err); got != http.StatusPaymentRequired {
+		t.Errorf("unexpected http status, got %d", got)
+	}
+	if got := mapper.GRPCCode(err); got != codes.FailedPrecondition {
+		t.Errorf("unexpected grpc code, got %s", got)
+	}
+	if got := mapper.FromHTTPStatus(http.StatusPaymentRequired); got != errPaymentRequired {
+		t.Errorf("unexpected error type, got %v", got)
+	}
+
+	if got := mapper.HTTPStatus(oops.Transient("oops")); got != http.StatusTooManyRequests {
+		t.Errorf("default mappings had to be overridden, got %d", got)
+	}
+	if got := mapper.FromGRPCCode(codes.ResourceExhausted); got != oops.ErrTransient {
+		t.Errorf("unexpected error type, got %v", got)
+	}
+
+	if got := HTTPStatus(oops.Transient("oops")); got != http.StatusServiceUnavailable {
+		t.Errorf("the default mapper can't be modified, got %d", got)
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/thisiserico/golib/kv"
 	"github.com/thisiserico/golib/oops"
 	"github.com/thisiserico/golib/pubsub"
 )
 
-var knownEventName = pubsub.Name("known")
+var (
+	knownEventName = pubsub.Name("known")
+	tenantKey      = kv.NewKey[string]("memory_test.tenant")
+)
 
 func TestConsumingWithACancelledContext(t *testing.T) {
 	var messageWasHandled bool
@@ -205,3 +209,32 @@ func TestUsingMultipleSubscribers(t *testing.T) {
 		t.Fatal("the handled events don't match")
 	}
 }
+
+func TestPropagatingCustomAttributes(t *testing.T) {
+	handled := make(chan context.Context, 1)
+	handler := func(ctx context.Context, _ pubsub.Event) error {
+		handled <- ctx
+		return nil
+	}
+	errHandler := func(_ context.Context, _ error, _ *pubsub.Event) {}
+
+	pub := NewPublisher()
+	sub := NewSubscriber()
+	defer sub.Close()
+
+	ctx := tenantKey.Set(context.Background(), "tenant")
+	_ = pub.Emit(context.Background(), pubsub.NewEvent(ctx, knownEventName, nil))
+
+	subCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sub.Consume(subCtx, handler, errHandler)
+
+	select {
+	case ctx := <-handled:
+		if got, _ := tenantKey.Get(ctx); got != "tenant" {
+			t.Fatalf("unexpected tenant, want tenant, got %s", got)
+		}
+	default:
+		t.Fatal("the event had to be handled")
+	}
+}
@@ -3,6 +3,7 @@ package pubsub
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,6 +29,10 @@ type Meta struct {
 
 	// IsDryRun indicates whether the execution is a dry run.
 	IsDryRun bool `json:"is_dry_run"`
+
+	// Attributes holds the json encoded context attributes for keys created
+	// by clients, indexed by key name.
+	Attributes map[string]json.RawMessage `json:"attributes,omitempty"`
 }
 
 // Event defines the event envelope.
@@ -46,8 +51,11 @@ type Event struct {
 }
 
 // NewEvent creates an event of the specified name that uses contextual
-// information and the given message.
+// information and the given message. A correlation ID is created when the
+// context doesn't hold a valid one.
 func NewEvent(ctx context.Context, name Name, msg []byte) Event {
+	ctx = kv.EnsureCorrelationID(ctx)
+
 	return Event{
 		ID:   ID(uuid.New().String()),
 		Name: name,
@@ -55,11 +63,42 @@ func NewEvent(ctx context.Context, name Name, msg []byte) Event {
 			CreatedAtUTC:  time.Now().UTC(),
 			CorrelationID: kv.CorrelationID(ctx).String(),
 			IsDryRun:      kv.IsDryRun(ctx).Bool(),
+			Attributes:    encodeAttributes(ctx),
 		},
 		Payload: msg,
 	}
 }
 
+// DecorateContext adds the contextual information that the event holds into
+// the given context. Attributes for unknown keys are ignored. An invalid
+// correlation ID is replaced by a new one.
+func DecorateContext(ctx context.Context, event Event) context.Context {
+	ctx = kv.SetDynamicAttributes(ctx, event.Meta.CorrelationID, event.Meta.IsDryRun)
+	ctx = kv.EnsureCorrelationID(ctx)
+	for name, data := range event.Meta.Attributes {
+		ctx, _ = kv.SetEncodedAttribute(ctx, name, data)
+	}
+
+	return ctx
+}
+
+func encodeAttributes(ctx context.Context) map[string]json.RawMessage {
+	var attrs map[string]json.RawMessage
+	for _, pair := range kv.CustomAttributes(ctx) {
+		data, err := json.Marshal(pair.Value())
+		if err != nil {
+			continue
+		}
+
+		if attrs == nil {
+			attrs = make(map[string]json.RawMessage)
+		}
+		attrs[pair.Name()] = data
+	}
+
+	return attrs
+}
+
 // Publisher defines the capabilities of any publisher.
 type Publisher interface {
 	// Emit publishes the given events to the stream.
@@ -0,0 +1,38 @@
+package pubsub
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/thisiserico/golib/kv"
+)
+
+func TestEventCorrelationID(t *testing.T) {
+	t.Run("creating an event without correlation ID", func(t *testing.T) {
+		event := NewEvent(context.Background(), "event", nil)
+
+		if err := kv.ValidateCorrelationID(event.Meta.CorrelationID); err != nil {
+			t.Fatalf("a valid correlation ID had to be created, got %s", err)
+		}
+	})
+
+	t.Run("creating an event with correlation ID", func(t *testing.T) {
+		ctx := kv.SetDynamicAttributes(context.Background(), "correlation_id", false)
+		event := NewEvent(ctx, "event", nil)
+
+		if got := event.Meta.CorrelationID; got != "correlation_id" {
+			t.Fatalf("unexpected correlation ID, got %s, want correlation_id", got)
+		}
+	})
+
+	t.Run("decorating a context with an invalid correlation ID", func(t *testing.T) {
+		event := NewEvent(context.Background(), "event", nil)
+		event.Meta.CorrelationID = strings.Repeat("a", kv.MaxCorrelationIDLength+1)
+
+		ctx := DecorateContext(context.Background(), event)
+		if got := kv.CorrelationID(ctx).String(); got == event.Meta.CorrelationID || kv.ValidateCorrelationID(got) != nil {
+			t.Fatalf("the correlation ID had to be replaced, got %s", got)
+		}
+	})
+}
@@ -372,7 +372,7 @@ func (s *subscriber) consumeSingleEntry(
 	var event pubsub.Event
 	_ = json.Unmarshal(fields[1].([]byte), &event)
 
-	ctx = kv.SetDynamicAttributes(ctx, event.Meta.CorrelationID, event.Meta.IsDryRun)
+	ctx = pubsub.DecorateContext(ctx, event)
 	span.SetAttributes(
 		attribute.String("pubsub.correlation_id", event.Meta.CorrelationID),
 		attribute.Bool("pubsub.is_dry_run", event.Meta.IsDryRun),